@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// Future is the result of a request served asynchronously by ServeAsync.
+type Future struct {
+	// served is done when res and err are set.
+	served sync.WaitGroup
+	res    Response
+	err    error
+
+	// mu guards done and complete.
+	mu sync.Mutex
+	// done is closed when res and err are set. It is created by the first call of Done, so that the Futures that
+	// are only waited for don't allocate it.
+	done chan struct{}
+	// complete is true once res and err are set.
+	complete bool
+}
+
+// ServeAsync serves the request in the background, exactly like Serve, and returns immediately a Future for
+// collecting the result later. This way several requests can be fanned out and joined afterwards.
+// The context is used for serving the request, so cancelling it makes the Future complete with the context error.
+func (s *Service) ServeAsync(ctx context.Context, req Request) *Future {
+	f := &Future{}
+	f.served.Add(1)
+	go func() {
+		defer f.finish()
+		f.res, f.err = s.Serve(ctx, req)
+	}()
+
+	return f
+}
+
+// Wait blocks until the request is served and returns the result of Serve.
+// It can be called any number of times, from any number of goroutines, and always returns the same result.
+func (f *Future) Wait() (Response, error) {
+	f.served.Wait()
+
+	return f.res, f.err
+}
+
+// Done returns a channel that is closed when the request is served, for selecting on the completion of the Future
+// together with other channels. Wait returns immediately after Done is closed.
+func (f *Future) Done() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.done == nil {
+		f.done = make(chan struct{})
+		if f.complete {
+			close(f.done)
+		}
+	}
+
+	return f.done
+}
+
+// finish marks the Future as complete, once res and err are set.
+func (f *Future) finish() {
+	f.mu.Lock()
+	f.complete = true
+	if f.done != nil {
+		close(f.done)
+	}
+	f.mu.Unlock()
+	f.served.Done()
+}
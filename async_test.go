@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test case for waiting on a Future before the request is served, from several goroutines
+func TestService_ServeAsync_WaitBeforeCompletion(t *testing.T) {
+	ts := &TestService{Res: Response{Data: "success"}, DelayReponse: 20 * time.Millisecond}
+	srv := NewServiceWithOptions(ts.Serve)
+
+	f := srv.ServeAsync(context.Background(), Request{Data: "request"})
+
+	select {
+	case <-f.Done():
+		t.Fatalf("the Future completed before the request was served")
+	default:
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := f.Wait()
+			if err != nil || !reflect.DeepEqual(response, Response{Data: "success"}) {
+				t.Errorf("Wait() got %v, %v, wanted %v, %v", response, err, Response{Data: "success"}, nil)
+			}
+		}()
+	}
+	wg.Wait()
+	if ts.Recorder.Calls != 1 {
+		t.Errorf("got %d calls, wanted %d", ts.Recorder.Calls, 1)
+	}
+}
+
+// Test case for waiting on a Future after the request is served
+func TestService_ServeAsync_WaitAfterCompletion(t *testing.T) {
+	workErr := errors.New("error")
+	ts := &TestService{Err: workErr}
+	srv := NewServiceWithOptions(ts.Serve)
+
+	f := srv.ServeAsync(context.Background(), Request{})
+	select {
+	case <-f.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("the Future did not complete")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := f.Wait(); !errors.Is(err, workErr) {
+			t.Errorf("Wait() got err %v, wanted %v", err, workErr)
+		}
+	}
+}
+
+// Test case for a Future completing with the context error when the context gets cancelled
+func TestService_ServeAsync_Cancelled(t *testing.T) {
+	ts := &TestService{DelayReponse: time.Second}
+	srv := NewServiceWithOptions(ts.Serve)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	f := srv.ServeAsync(ctx, Request{})
+	cancel()
+
+	if _, err := f.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() got err %v, wanted %v", err, context.Canceled)
+	}
+}
+
+// Test case for Done called for the first time after the request is served. The channel is already closed.
+func TestService_ServeAsync_DoneAfterWait(t *testing.T) {
+	srv := NewServiceWithOptions(noopWork)
+
+	f := srv.ServeAsync(context.Background(), Request{})
+	f.Wait()
+
+	select {
+	case <-f.Done():
+	default:
+		t.Errorf("Done() got an open channel after Wait returned, wanted it closed")
+	}
+}
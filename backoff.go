@@ -0,0 +1,58 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// WithExponentialBackoff is an option that makes the wait between two attempts of WithRetry grow exponentially,
+// instead of being constant. See ExponentialBackoff for how the wait is calculated.
+// jitter enables full jitter, which spreads the retries of concurrent requests over time instead of sending
+// them all together to a downstream service that already struggles.
+// It has no effect without WithRetry, and takes precedence over the backoff passed to WithRetry.
+func WithExponentialBackoff(base time.Duration, max time.Duration, jitter bool) Option {
+	return func(s *Service) {
+		s.backoff = func(retry int) time.Duration {
+			// A nil rand source makes ExponentialBackoff use the default source, which is safe for concurrent use.
+			return ExponentialBackoff(retry, base, max, jitter, nil)
+		}
+	}
+}
+
+// ExponentialBackoff calculates the wait before the given retry, where retry 0 is the wait before the second attempt.
+// The wait is min(base*2^retry, max), where a max lower or equal to 0 means that the wait is not capped.
+// When jitter is true the wait is a uniform random duration in [0, min(base*2^retry, max)], picked using rnd.
+// A nil rnd uses the default source of the math/rand package.
+func ExponentialBackoff(retry int, base, max time.Duration, jitter bool, rnd *rand.Rand) time.Duration {
+	d := base
+	for i := 0; i < retry && d > 0; i++ {
+		if max > 0 && d >= max {
+			break
+		}
+		// Stop doubling before overflowing.
+		if d > math.MaxInt64/2 {
+			d = math.MaxInt64
+			break
+		}
+		d *= 2
+	}
+	if max > 0 && d > max {
+		d = max
+	}
+
+	if !jitter || d <= 0 {
+		return d
+	}
+
+	// Int63n returns a value in [0, n), so add 1 in order to include d in the range, without overflowing.
+	n := int64(d)
+	if n < math.MaxInt64 {
+		n++
+	}
+	if rnd == nil {
+		return time.Duration(rand.Int63n(n))
+	}
+
+	return time.Duration(rnd.Int63n(n))
+}
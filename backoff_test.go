@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	tests := []struct {
+		name  string
+		retry int
+		base  time.Duration
+		max   time.Duration
+		want  time.Duration
+	}{
+		{name: "first retry waits base", retry: 0, base: 10 * time.Millisecond, max: time.Second, want: 10 * time.Millisecond},
+		{name: "second retry doubles", retry: 1, base: 10 * time.Millisecond, max: time.Second, want: 20 * time.Millisecond},
+		{name: "fourth retry", retry: 3, base: 10 * time.Millisecond, max: time.Second, want: 80 * time.Millisecond},
+		{name: "capped to max", retry: 10, base: 10 * time.Millisecond, max: time.Second, want: time.Second},
+		{name: "no max", retry: 10, base: 10 * time.Millisecond, max: 0, want: 10240 * time.Millisecond},
+		{name: "no overflow", retry: 1000, base: time.Second, max: 0, want: math.MaxInt64},
+		{name: "zero base", retry: 3, base: 0, max: time.Second, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExponentialBackoff(tt.retry, tt.base, tt.max, false, nil); got != tt.want {
+				t.Errorf("ExponentialBackoff() got %v, wanted %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff_Jitter(t *testing.T) {
+	// The same seed must produce the same sequence of waits.
+	rnd1 := rand.New(rand.NewSource(1))
+	rnd2 := rand.New(rand.NewSource(1))
+	for retry := 0; retry < 20; retry++ {
+		computed := ExponentialBackoff(retry, 10*time.Millisecond, time.Second, false, nil)
+		got := ExponentialBackoff(retry, 10*time.Millisecond, time.Second, true, rnd1)
+		if got < 0 || got > computed {
+			t.Errorf("ExponentialBackoff() got %v, wanted a value in [0, %v]", got, computed)
+		}
+		if again := ExponentialBackoff(retry, 10*time.Millisecond, time.Second, true, rnd2); again != got {
+			t.Errorf("ExponentialBackoff() got %v with the same seed, wanted %v", again, got)
+		}
+	}
+}
+
+// Test case for a work that always fails with exponential backoff. The waits between the attempts add up.
+func TestService_Serve_ExponentialBackoff(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, errors.New("error")
+	}, WithRetry(4, 0), WithExponentialBackoff(10*time.Millisecond, 20*time.Millisecond, false))
+
+	start := time.Now()
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if err == nil {
+		t.Errorf("Serve() should return an error")
+	}
+	// 10ms + 20ms + 20ms (capped)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Serve() returned after %v, wanted at least %v", elapsed, 50*time.Millisecond)
+	}
+	if srv.Attempts() != 4 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 4)
+	}
+}
+
+// Test case for a cancellation during an exponential backoff. The wait is interrupted.
+func TestService_Serve_ExponentialBackoffCancelled(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, errors.New("error")
+	}, WithRetry(4, 0), WithExponentialBackoff(2000*time.Millisecond, 0, false))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > 1000*time.Millisecond {
+		t.Errorf("Serve() returned after %v, wanted the backoff to be interrupted", elapsed)
+	}
+}
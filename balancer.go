@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultHealthWindow is the time a failing backend is skipped by a Balancer, unless WithHealthWindow is used.
+const defaultHealthWindow = 5 * time.Second
+
+// Backend is a Server along with its weight, which is its share of the requests routed by a Balancer relative to
+// the other backends, e.g. according to its capacity. A weight lower than 1 is treated as 1.
+type Backend struct {
+	Server Server
+	Weight int
+}
+
+// BalancerOption configures the optional features of a Balancer.
+type BalancerOption func(*Balancer)
+
+// WithHealthWindow is an option that sets the time a backend is skipped after a failure, 5 seconds by default.
+// A d lower or equal to 0 never skips a backend.
+func WithHealthWindow(d time.Duration) BalancerOption {
+	return func(b *Balancer) {
+		b.healthWindow = d
+	}
+}
+
+// WithBalancerClock is an option that replaces the source of time of the Balancer, e.g. with a FakeClock in tests.
+func WithBalancerClock(clock Clock) BalancerOption {
+	return func(b *Balancer) {
+		b.clock = clock
+	}
+}
+
+// Balancer is a Server routing every request to one of its backends, chosen by smooth weighted round-robin, so that
+// every backend gets a share of the requests proportional to its weight, interleaved with the requests of the rest
+// of the backends instead of in bursts.
+// A backend that returns an error is considered unhealthy and it is skipped for the health window (see
+// WithHealthWindow), or until it passes a health check if it failed one (see StartHealthPolling), unless every
+// backend is unhealthy, in which case they are all used as if they were healthy.
+// Errors returned after the context of the caller is done don't count as failures of the backend.
+// It is safe for concurrent use.
+type Balancer struct {
+	healthWindow time.Duration
+	clock        Clock
+	// onHealthChange is called when the polling finds that a backend changed health. See WithHealthCallback.
+	onHealthChange func(server Server, healthy bool)
+
+	// mu guards the state of the backends.
+	mu       sync.Mutex
+	backends []*backend
+}
+
+// backend is a Backend along with its state in a Balancer.
+type backend struct {
+	Backend
+	// current is the current weight of the smooth weighted round-robin.
+	current int
+	// unhealthyUntil is the time until which the backend is skipped.
+	unhealthyUntil time.Time
+	// down is true if the last health poll failed. See StartHealthPolling.
+	down bool
+}
+
+// NewBalancer is a factory function/constructor for a Balancer routing the requests to the backends.
+// Calling Serve on a Balancer without backends returns ErrNoServers.
+func NewBalancer(backends []Backend, opts ...BalancerOption) *Balancer {
+	b := &Balancer{
+		healthWindow: defaultHealthWindow,
+		clock:        realClock{},
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	for _, be := range backends {
+		if be.Weight < 1 {
+			be.Weight = 1
+		}
+		b.backends = append(b.backends, &backend{Backend: be})
+	}
+
+	return b
+}
+
+// Serve sends the request to the next backend and returns its response and error, wrapped with the name of the
+// backend if it has one (see Named).
+func (b *Balancer) Serve(ctx context.Context, req Request) (Response, error) {
+	be := b.next()
+	if be == nil {
+		return Response{}, ErrNoServers
+	}
+
+	res, err := be.Server.Serve(ctx, req)
+	if err != nil && ctx.Err() == nil {
+		b.markUnhealthy(be)
+	}
+
+	return res, withServerName(be.Server, err)
+}
+
+// next returns the backend chosen by smooth weighted round-robin among the healthy backends, or among all of them
+// if none is healthy. It returns nil if there are no backends.
+func (b *Balancer) next() *backend {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	healthy := make([]*backend, 0, len(b.backends))
+	for _, be := range b.backends {
+		if !be.down && !now.Before(be.unhealthyUntil) {
+			healthy = append(healthy, be)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = b.backends
+	}
+
+	// Every backend gains its weight, and the one with the highest current weight is picked and loses the total
+	// weight, so it is picked again only after the rest of the backends had their share.
+	var picked *backend
+	total := 0
+	for _, be := range healthy {
+		be.current += be.Weight
+		total += be.Weight
+		if picked == nil || be.current > picked.current {
+			picked = be
+		}
+	}
+	if picked != nil {
+		picked.current -= total
+	}
+
+	return picked
+}
+
+// markUnhealthy skips the backend for the health window.
+func (b *Balancer) markUnhealthy(be *backend) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	be.unhealthyUntil = b.clock.Now().Add(b.healthWindow)
+}
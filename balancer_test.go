@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test case for the distribution of many calls among weighted backends. Every backend gets its share.
+func TestBalancer_Serve_Weights(t *testing.T) {
+	servers := []*TestService{{Synchronous: true}, {Synchronous: true}, {Synchronous: true}}
+	b := NewBalancer([]Backend{
+		{Server: servers[0], Weight: 5},
+		{Server: servers[1], Weight: 3},
+		{Server: servers[2], Weight: 0},
+	})
+
+	for i := 0; i < 900; i++ {
+		if _, err := b.Serve(context.Background(), Request{}); err != nil {
+			t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+		}
+	}
+
+	for i, want := range []int{500, 300, 100} {
+		if got := servers[i].Snapshot().Calls; got != want {
+			t.Errorf("backend %d got %d calls, wanted %d", i, got, want)
+		}
+	}
+}
+
+// Test case for a failing backend. It is skipped for the health window, and then it is used again.
+func TestBalancer_Serve_Unhealthy(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	failing := &TestService{Synchronous: true, Errs: []error{errors.New("error")}}
+	healthy := &TestService{Synchronous: true}
+	b := NewBalancer([]Backend{
+		{Server: failing, Weight: 1},
+		{Server: healthy, Weight: 1},
+	}, WithHealthWindow(time.Minute), WithBalancerClock(clock))
+
+	for i := 0; i < 10; i++ {
+		b.Serve(context.Background(), Request{})
+	}
+	if got := failing.Snapshot().Calls; got != 1 {
+		t.Errorf("failing backend got %d calls, wanted %d", got, 1)
+	}
+
+	clock.Advance(time.Minute)
+	for i := 0; i < 10; i++ {
+		b.Serve(context.Background(), Request{})
+	}
+	if got := failing.Snapshot().Calls; got < 5 {
+		t.Errorf("recovered backend got %d calls, wanted at least %d", got, 5)
+	}
+}
+
+// Test case for all the backends being unhealthy. They are all used anyway.
+func TestBalancer_Serve_AllUnhealthy(t *testing.T) {
+	wantErr := errors.New("error")
+	b := NewBalancer([]Backend{{Server: &TestService{Synchronous: true, Err: wantErr}, Weight: 1}})
+
+	for i := 0; i < 2; i++ {
+		if _, err := b.Serve(context.Background(), Request{}); err != wantErr {
+			t.Errorf("Serve() got err %v, wanted %v", err, wantErr)
+		}
+	}
+}
+
+// Test case for a Balancer without backends
+func TestBalancer_Serve_NoBackends(t *testing.T) {
+	if _, err := NewBalancer(nil).Serve(context.Background(), Request{}); err != ErrNoServers {
+		t.Errorf("Serve() got err %v, wanted %v", err, ErrNoServers)
+	}
+}
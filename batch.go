@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// WithBatchConcurrency is an option that limits the number of requests of a ServeBatch call served at the same time.
+// A n lower or equal to 0, which is the default, serves all the requests of the batch at the same time.
+func WithBatchConcurrency(n int) Option {
+	return func(s *Service) {
+		s.batchConcurrency = n
+	}
+}
+
+// ServeBatch serves many requests with a shared context, concurrently, and returns their responses and errors
+// aligned by index with the requests: responses[i] and errs[i] are the outcome of reqs[i].
+// Every request is served like Serve, so partial success is possible and the errors must be checked one by one.
+// If the context gets cancelled, the requests being served return the context error, and so do the requests
+// that were not launched yet, along with the cause of the cancellation if there is one.
+func (s *Service) ServeBatch(ctx context.Context, reqs []Request) ([]Response, []error) {
+	responses := make([]Response, len(reqs))
+	errs := make([]error, len(reqs))
+
+	var sem chan struct{}
+	if s.batchConcurrency > 0 {
+		sem = make(chan struct{}, s.batchConcurrency)
+	}
+
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				// Don't launch the rest of the requests.
+				for j := i; j < len(reqs); j++ {
+					errs[j] = contextErr(ctx)
+				}
+				wg.Wait()
+				return responses, errs
+			}
+		}
+
+		wg.Add(1)
+		go func(i int, req Request) {
+			defer wg.Done()
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+			responses[i], errs[i] = s.Serve(ctx, req)
+		}(i, req)
+	}
+	wg.Wait()
+
+	return responses, errs
+}
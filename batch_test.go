@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test case for a batch with partial success. The outcomes are aligned with the requests.
+func TestService_ServeBatch(t *testing.T) {
+	workErr := errors.New("error")
+	srv := NewRequestService(func(req Request) (Response, error) {
+		if req.Data == "fail" {
+			return Response{}, workErr
+		}
+		return Response{Data: "response " + req.Data}, nil
+	})
+
+	responses, errs := srv.ServeBatch(context.Background(), []Request{{Data: "a"}, {Data: "fail"}, {Data: "c"}})
+
+	wantResponses := []Response{{Data: "response a"}, {}, {Data: "response c"}}
+	if !reflect.DeepEqual(responses, wantResponses) {
+		t.Errorf("ServeBatch() got responses %v, wanted %v", responses, wantResponses)
+	}
+	wantErrs := []error{nil, workErr, nil}
+	if !reflect.DeepEqual(errs, wantErrs) {
+		t.Errorf("ServeBatch() got errors %v, wanted %v", errs, wantErrs)
+	}
+}
+
+// Test case for a batch with a concurrency limit. No more than the limit of requests are served at the same time.
+func TestService_ServeBatch_Concurrency(t *testing.T) {
+	var running, maxRunning int64
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		n := atomic.AddInt64(&running, 1)
+		defer atomic.AddInt64(&running, -1)
+		for {
+			m := atomic.LoadInt64(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt64(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return Response{}, nil
+	}, WithBatchConcurrency(2))
+
+	_, errs := srv.ServeBatch(context.Background(), make([]Request, 10))
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("ServeBatch() got error %v at %d", err, i)
+		}
+	}
+	if maxRunning != 2 {
+		t.Errorf("got %d requests served at the same time, wanted %d", maxRunning, 2)
+	}
+}
+
+// Test case for a batch whose context times out. The outstanding and the remaining requests get the context error.
+func TestService_ServeBatch_Timeout(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		if req.Data == "fast" {
+			return Response{Data: "success"}, nil
+		}
+		<-ctx.Done()
+		return Response{}, ctx.Err()
+	}, WithBatchConcurrency(2))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	responses, errs := srv.ServeBatch(ctx, []Request{{Data: "fast"}, {Data: "slow"}, {Data: "slow"}, {Data: "slow"}})
+
+	if errs[0] != nil || responses[0].Data != "success" {
+		t.Errorf("ServeBatch() got %v, %v at 0, wanted success", responses[0], errs[0])
+	}
+	for i := 1; i < 4; i++ {
+		if !errors.Is(errs[i], context.DeadlineExceeded) {
+			t.Errorf("ServeBatch() got error %v at %d, wanted %v", errs[i], i, context.DeadlineExceeded)
+		}
+	}
+}
+
+// Test case for a batch whose context gets cancelled with a cause. The requests that were not launched get the cause.
+func TestService_ServeBatch_CancelCause(t *testing.T) {
+	cause := errors.New("client went away")
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		<-ctx.Done()
+		return Response{}, ctx.Err()
+	}, WithBatchConcurrency(1))
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(10*time.Millisecond, func() { cancel(cause) })
+
+	_, errs := srv.ServeBatch(ctx, []Request{{Data: "first"}, {Data: "second"}, {Data: "third"}})
+
+	for i, err := range errs {
+		if !errors.Is(err, context.Canceled) || !errors.Is(err, cause) {
+			t.Errorf("ServeBatch() got error %v at %d, wanted %v and %v", err, i, context.Canceled, cause)
+		}
+	}
+}
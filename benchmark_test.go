@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// noopWork is a work that returns immediately, so that the benchmarks measure the overhead of Serve.
+func noopWork(ctx context.Context, req Request) (Response, error) {
+	return Response{}, nil
+}
+
+// Benchmark of Serve called by a single goroutine
+func BenchmarkServe_NoContention(b *testing.B) {
+	srv := NewServiceWithOptions(noopWork)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		srv.Serve(ctx, Request{})
+	}
+}
+
+// Benchmark of Serve called by many more goroutines than the available CPUs
+func BenchmarkServe_HighConcurrency(b *testing.B) {
+	srv := NewServiceWithOptions(noopWork)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.SetParallelism(100)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			srv.Serve(ctx, Request{})
+		}
+	})
+}
+
+// Benchmark of Serve called with a context with a deadline, including the cost of the context
+func BenchmarkServe_WithTimeout(b *testing.B) {
+	srv := NewServiceWithOptions(noopWork)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			srv.Serve(ctx, Request{})
+			cancel()
+		}
+	})
+}
+
+// Benchmark of ServeInto called by a single goroutine, to compare with BenchmarkServe_NoContention
+func BenchmarkServeInto_NoContention(b *testing.B) {
+	srv := NewServiceWithOptions(noopWork)
+	ctx := context.Background()
+	var out Response
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		srv.ServeInto(ctx, Request{}, &out)
+	}
+}
+
+// Benchmark of fanning out requests with ServeAsync and joining them, with the default pool of result channels
+func BenchmarkServeAsync(b *testing.B) {
+	benchmarkServeAsync(b, NewServiceWithOptions(noopWork))
+}
+
+// Benchmark of fanning out requests with ServeAsync and joining them, with a pre-warmed pool of result channels
+func BenchmarkServeAsync_ResultPool(b *testing.B) {
+	benchmarkServeAsync(b, NewServiceWithOptions(noopWork, WithResultPool(64)))
+}
+
+// benchmarkServeAsync fans out batches of 64 requests with ServeAsync and waits for all of them, forcing a garbage
+// collection after every batch, like a workload under memory pressure, which empties the default pool.
+func benchmarkServeAsync(b *testing.B, srv *Service) {
+	ctx := context.Background()
+	futures := make([]*Future, 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range futures {
+			futures[j] = srv.ServeAsync(ctx, Request{})
+		}
+		for _, f := range futures {
+			f.Wait()
+		}
+		runtime.GC()
+	}
+}
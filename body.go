@@ -0,0 +1,52 @@
+package service
+
+import (
+	"io"
+	"time"
+)
+
+// maxDrain is the maximum number of bytes read by DrainAndClose, so that draining a huge or endless body doesn't
+// take forever.
+const maxDrain = 256 << 10
+
+// DrainAndClose reads and discards what is left of the reader, up to 256KB, and closes it if it is an io.Closer.
+// Draining lets the underlying connection be reused, e.g. for an HTTP response body, while the limit makes sure that
+// a huge or endless body is closed instead of being read forever. It does nothing if the reader is nil.
+// It returns the error of the close, or of the read if the close succeeds.
+func DrainAndClose(r io.Reader) error {
+	if r == nil {
+		return nil
+	}
+
+	_, err := io.CopyN(io.Discard, r, maxDrain)
+	if err == io.EOF {
+		err = nil
+	}
+	if c, ok := r.(io.Closer); ok {
+		if cerr := c.Close(); cerr != nil {
+			return cerr
+		}
+	}
+
+	return err
+}
+
+// discardAbandoned waits for the outcome of abandoned work and releases the bodies of the request and the response,
+// since nobody is going to read them anymore. It is launched when Serve stops waiting for the work, at abandoned.
+// The outcome is passed to the late result handler first, if there is one, and its delay is recorded in the metrics.
+// See WithLateResultHandler and WithMetrics. The channel is reused once the outcome is received.
+func (s *Service) discardAbandoned(req Request, resultCh chan result, abandoned time.Time) {
+	defer s.inflight.Done()
+
+	r := <-resultCh
+	s.putResultChan(resultCh)
+	lateBy := s.clock.Now().Sub(abandoned)
+	if s.metrics != nil {
+		s.metrics.overrun.Observe(lateBy.Seconds())
+	}
+	if s.lateResultHandler != nil {
+		s.lateResultHandler(r.res, r.err, lateBy)
+	}
+	DrainAndClose(r.res.Body)
+	DrainAndClose(req.Body)
+}
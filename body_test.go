@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// trackedBody is a body recording if it has been closed.
+type trackedBody struct {
+	io.Reader
+	closed int32
+}
+
+// Close records the close.
+func (b *trackedBody) Close() error {
+	atomic.StoreInt32(&b.closed, 1)
+	return nil
+}
+
+// isClosed reports if the body has been closed.
+func (b *trackedBody) isClosed() bool {
+	return atomic.LoadInt32(&b.closed) == 1
+}
+
+// endless is a reader that never ends.
+type endless struct{}
+
+// Read fills p with zeros.
+func (endless) Read(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// Test case for draining and closing readers
+func TestDrainAndClose(t *testing.T) {
+	body := &trackedBody{Reader: strings.NewReader("body")}
+	if err := DrainAndClose(body); err != nil {
+		t.Errorf("DrainAndClose() got err %v, wanted %v", err, nil)
+	}
+	if !body.isClosed() {
+		t.Errorf("the body should be closed")
+	}
+	if n, _ := body.Read(make([]byte, 1)); n != 0 {
+		t.Errorf("the body should be drained")
+	}
+
+	endlessBody := &trackedBody{Reader: endless{}}
+	if err := DrainAndClose(endlessBody); err != nil {
+		t.Errorf("DrainAndClose() got err %v, wanted %v", err, nil)
+	}
+	if !endlessBody.isClosed() {
+		t.Errorf("the endless body should be closed")
+	}
+
+	if err := DrainAndClose(nil); err != nil {
+		t.Errorf("DrainAndClose() got err %v, wanted %v", err, nil)
+	}
+}
+
+// Test case for the error of the close
+func TestDrainAndClose_CloseError(t *testing.T) {
+	closeErr := errors.New("close error")
+	body := struct {
+		io.Reader
+		io.Closer
+	}{strings.NewReader("body"), closerFunc(func() error { return closeErr })}
+
+	if err := DrainAndClose(body); err != closeErr {
+		t.Errorf("DrainAndClose() got err %v, wanted %v", err, closeErr)
+	}
+}
+
+// closerFunc adapts a function to an io.Closer.
+type closerFunc func() error
+
+// Close calls f.
+func (f closerFunc) Close() error {
+	return f()
+}
+
+// Test case for the bodies of abandoned work. They are drained and closed when the work returns.
+func TestService_Serve_AbandonedBodies(t *testing.T) {
+	release := make(chan struct{})
+	resBody := &trackedBody{Reader: strings.NewReader("response")}
+	srv := NewService(func() (Response, error) {
+		<-release
+		return Response{Body: resBody}, nil
+	})
+	reqBody := &trackedBody{Reader: strings.NewReader("request")}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := srv.Serve(ctx, Request{Body: reqBody})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if reqBody.isClosed() || resBody.isClosed() {
+		t.Errorf("the bodies should not be closed before the work returns")
+	}
+
+	close(release)
+	waitFor(t, func() bool { return reqBody.isClosed() && resBody.isClosed() })
+}
+
+// Test case for the body of a successful response. It is handed over to the caller untouched.
+func TestService_Serve_ResponseBody(t *testing.T) {
+	srv := NewService(func() (Response, error) {
+		return Response{Body: strings.NewReader("response")}, nil
+	})
+
+	res, err := srv.Serve(context.Background(), Request{})
+	if err != nil {
+		t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+	}
+
+	body, _ := io.ReadAll(res.Body)
+	if string(body) != "response" {
+		t.Errorf("got body %q, wanted %q", body, "response")
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInsufficientBudget is the error returned by Serve when the remaining time until the deadline of the context is
+// less than the minimum budget set with WithMinBudget.
+var ErrInsufficientBudget = errors.New("service: insufficient time budget")
+
+// RemainingBudget returns the time left until the deadline of the context, and whether the context has a deadline.
+// The work can use it to decide whether there is enough time to attempt an expensive step.
+// The returned duration is negative if the deadline has already passed, and 0 if there is no deadline.
+func RemainingBudget(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+
+	return time.Until(deadline), true
+}
+
+// WithMinBudget is an option that makes Serve return ErrInsufficientBudget immediately, without launching the work,
+// when the remaining time until the deadline of the context is less than d, since the work can't possibly finish
+// in time. Requests with contexts without a deadline are always served. Requests found in the cache are served
+// regardless of the remaining time, since they don't launch the work.
+func WithMinBudget(d time.Duration) Option {
+	return func(s *Service) {
+		s.minBudget = d
+	}
+}
+
+// checkBudget returns ErrInsufficientBudget if the remaining time of the context is less than the minimum budget.
+func (s *Service) checkBudget(ctx context.Context) error {
+	if s.minBudget <= 0 {
+		return nil
+	}
+	if remaining, ok := RemainingBudget(ctx); ok && remaining < s.minBudget {
+		return ErrInsufficientBudget
+	}
+
+	return nil
+}
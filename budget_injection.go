@@ -0,0 +1,32 @@
+package service
+
+import "context"
+
+// WithBudgetInjection is an option that sets Request.Budget to the time left until the deadline of the context every
+// time the work is called, including the retries and the hedged copies, so that the work can choose a cheaper
+// algorithm when the deadline is tight. The Budget is 0, meaning unbounded, when the context has no deadline, and it
+// is negative when the deadline has already passed. Without this option the Budget is passed to the work unchanged.
+func WithBudgetInjection() Option {
+	return func(s *Service) {
+		s.budgetInjection = true
+	}
+}
+
+// injectBudget returns the request with the Budget set to the remaining time of the context, if budget injection is
+// enabled.
+func (s *Service) injectBudget(ctx context.Context, req Request) Request {
+	if !s.budgetInjection {
+		return req
+	}
+	if remaining, ok := RemainingBudget(ctx); ok {
+		// Keep an expired budget distinguishable from an unbounded one.
+		if remaining == 0 {
+			remaining = -1
+		}
+		req.Budget = remaining
+		return req
+	}
+	req.Budget = 0
+
+	return req
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test case for the budget injected into the request, with and without a deadline
+func TestService_Serve_BudgetInjection(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		opts    []Option
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{name: "deadline", timeout: time.Minute, opts: []Option{WithBudgetInjection()}, wantMin: time.Second, wantMax: time.Minute},
+		{name: "no deadline", opts: []Option{WithBudgetInjection()}, wantMin: 0, wantMax: 0},
+		{name: "disabled", timeout: time.Minute, wantMin: 0, wantMax: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := &TestService{}
+			srv := NewServiceWithOptions(ts.Serve, tt.opts...)
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			if _, err := srv.Serve(ctx, Request{}); err != nil {
+				t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+			}
+
+			got := ts.Snapshot().Request.Budget
+			if got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("got budget %v, wanted between %v and %v", got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+// Test case for the budget of a retry. It is computed again for every attempt.
+func TestService_Serve_BudgetInjectionRetry(t *testing.T) {
+	var budgets []time.Duration
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		budgets = append(budgets, req.Budget)
+		if len(budgets) == 1 {
+			time.Sleep(20 * time.Millisecond)
+			return Response{}, errors.New("error")
+		}
+		return Response{}, nil
+	}, WithRetry(2, 0), WithBudgetInjection())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if _, err := srv.Serve(ctx, Request{}); err != nil {
+		t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+	}
+
+	if len(budgets) != 2 || budgets[1] >= budgets[0] {
+		t.Errorf("got budgets %v, wanted a smaller budget for the retry", budgets)
+	}
+}
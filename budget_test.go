@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRemainingBudget(t *testing.T) {
+	remaining, ok := RemainingBudget(context.Background())
+	if ok || remaining != 0 {
+		t.Errorf("RemainingBudget() got %v, %v, wanted %v, %v", remaining, ok, 0, false)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	remaining, ok = RemainingBudget(ctx)
+	if !ok || remaining <= 59*time.Second || remaining > time.Minute {
+		t.Errorf("RemainingBudget() got %v, %v, wanted about %v, %v", remaining, ok, time.Minute, true)
+	}
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	remaining, ok = RemainingBudget(ctx)
+	if !ok || remaining >= 0 {
+		t.Errorf("RemainingBudget() got %v, %v, wanted a negative duration and %v", remaining, ok, true)
+	}
+}
+
+// Test case for contexts with various remaining budgets. Work is launched only with enough budget.
+func TestService_Serve_MinBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		wantErr error
+	}{
+		{name: "no deadline", timeout: 0, wantErr: nil},
+		{name: "enough budget", timeout: time.Second, wantErr: nil},
+		{name: "insufficient budget", timeout: 50 * time.Millisecond, wantErr: ErrInsufficientBudget},
+		{name: "expired", timeout: -time.Second, wantErr: ErrInsufficientBudget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+				return Response{Data: "success"}, nil
+			}, WithMinBudget(100*time.Millisecond))
+
+			ctx := context.Background()
+			if tt.timeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			_, err := srv.Serve(ctx, Request{})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Serve() got err %v, wanted %v", err, tt.wantErr)
+			}
+			wantAttempts := 1
+			if tt.wantErr != nil {
+				wantAttempts = 0
+			}
+			if srv.Attempts() != wantAttempts {
+				t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), wantAttempts)
+			}
+		})
+	}
+}
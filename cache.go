@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Cache is a store of responses by key, e.g. backed by Redis or memcached, so that the cached responses survive the
+// restarts and are shared by many instances of the Service. It must be safe for concurrent use.
+// The default Cache of WithCache is a MemoryCache.
+type Cache interface {
+	// Get returns the response stored under key and true, or false if there is none or it has expired.
+	Get(ctx context.Context, key string) (Response, bool, error)
+	// Set stores the response under key for ttl.
+	Set(ctx context.Context, key string, res Response, ttl time.Duration) error
+}
+
+// WithCache is an option that caches the successful responses of the work for ttl, so that identical requests
+// don't recompute the same response. keyFn returns the cache key of a request, and requests with the same key
+// are considered identical. A nil keyFn uses the key function of WithKeyFunc, or Fingerprint by default.
+// On a hit the cached response is returned without calling the work. On a miss the request is served normally
+// and, if it succeeds, the response is cached. Fallback responses are never cached, and neither are errors unless
+// WithNegativeCache is used.
+// The responses are cached in a MemoryCache, unless WithCacheBackend is used.
+func WithCache(ttl time.Duration, keyFn func(Request) string) Option {
+	return func(s *Service) {
+		s.cache = &responseCache{
+			ttl:      ttl,
+			keyFn:    keyFn,
+			backend:  NewMemoryCache(),
+			negative: make(map[string]cacheEntry),
+			clock:    realClock{},
+		}
+	}
+}
+
+// WithCacheBackend is an option that caches the responses of WithCache in backend instead of a MemoryCache.
+// The errors of WithNegativeCache are still cached in memory, since errors can't be stored in an external backend.
+// An error of the backend is treated as a miss, so the request is served by the work instead of failing, unless
+// WithCacheStrictness(true) is used. It has no effect without WithCache.
+func WithCacheBackend(backend Cache) Option {
+	return func(s *Service) {
+		s.cacheBackend = backend
+	}
+}
+
+// WithCacheStrictness is an option that makes Serve return the errors of the cache backend (see WithCacheBackend)
+// wrapped, instead of ignoring them, for the callers that prefer failing to hammering the work when the cache is
+// down. A failure to store the response fails the request too, even though the work succeeded.
+func WithCacheStrictness(strict bool) Option {
+	return func(s *Service) {
+		s.cacheStrict = strict
+	}
+}
+
+// CacheStats holds the statistics of the cache of a Service.
+type CacheStats struct {
+	// Hits is the number of requests served from the cache.
+	Hits int
+	// Misses is the number of requests not found in the cache, including the ignored errors of the cache backend.
+	Misses int
+}
+
+// CacheStats returns the statistics of the cache. They are always zero without WithCache.
+func (s *Service) CacheStats() CacheStats {
+	if s.cache == nil {
+		return CacheStats{}
+	}
+
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+
+	return s.cache.stats
+}
+
+// MemoryCache is an in-memory Cache, safe for concurrent use. It is the default Cache of WithCache.
+// Expired entries are evicted lazily, when they are read.
+type MemoryCache struct {
+	// clock is the source of time, set to the clock of the Service when it is the default Cache.
+	clock Clock
+	// onEvict is called with mu held when an expired entry is evicted, set by the Service for its metrics.
+	onEvict func()
+
+	// mu guards entries.
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewMemoryCache is a factory function/constructor for an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		clock:   realClock{},
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Get returns the response stored under key, if there is one that has not expired. It never returns an error.
+func (c *MemoryCache) Get(_ context.Context, key string) (Response, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && !c.clock.Now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		ok = false
+		if c.onEvict != nil {
+			c.onEvict()
+		}
+	}
+
+	return entry.res, ok, nil
+}
+
+// Len returns the number of entries in the cache, including the expired entries not evicted yet.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+// Set stores the response under key for ttl. It never returns an error.
+func (c *MemoryCache) Set(_ context.Context, key string, res Response, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cacheEntry{
+		res:       res,
+		expiresAt: c.clock.Now().Add(ttl),
+	}
+
+	return nil
+}
+
+// responseCache caches the responses of the requests in a Cache, and their errors in memory, safe for concurrent
+// use.
+type responseCache struct {
+	ttl     time.Duration
+	keyFn   func(Request) string
+	backend Cache
+	// strict makes the errors of the backend fail the request.
+	strict bool
+	// clock is the source of time, set to the clock of the Service.
+	clock Clock
+
+	// mu guards the fields below.
+	mu sync.Mutex
+	// negative holds the cached errors. See WithNegativeCache.
+	negative map[string]cacheEntry
+	stats    CacheStats
+	// metrics records the effectiveness of the cache, nil without WithMetrics.
+	metrics *cacheMetrics
+}
+
+// cacheEntry is a cached response, or a cached error (see WithNegativeCache), along with its expiration time.
+type cacheEntry struct {
+	res       Response
+	err       error
+	expiresAt time.Time
+}
+
+// get returns the cached entry of the request, if there is one that has not expired. The error of the backend is
+// returned only in strict mode, otherwise it is treated as a miss.
+func (c *responseCache) get(ctx context.Context, req Request) (cacheEntry, bool, error) {
+	key := c.keyFn(req)
+
+	c.mu.Lock()
+	entry, ok := c.negative[key]
+	if ok && !c.clock.Now().Before(entry.expiresAt) {
+		delete(c.negative, key)
+		ok = false
+		if c.metrics != nil {
+			c.metrics.evictions.Inc()
+		}
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		var err error
+		entry = cacheEntry{}
+		entry.res, ok, err = c.backend.Get(ctx, key)
+		if err != nil && c.strict {
+			return cacheEntry{}, false, fmt.Errorf("service: cache get: %w", err)
+		}
+		ok = ok && err == nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !ok {
+		c.stats.Misses++
+		if c.metrics != nil {
+			c.metrics.misses.Inc()
+		}
+		return cacheEntry{}, false, nil
+	}
+	c.stats.Hits++
+	if c.metrics != nil {
+		c.metrics.hits.Inc()
+	}
+
+	return entry, true, nil
+}
+
+// set caches the response of the request. The error of the backend is returned only in strict mode.
+func (c *responseCache) set(ctx context.Context, req Request, res Response) error {
+	if err := c.backend.Set(ctx, c.keyFn(req), res, c.ttl); err != nil && c.strict {
+		return fmt.Errorf("service: cache set: %w", err)
+	}
+
+	return nil
+}
+
+// useMetrics records the effectiveness of the cache in the metrics, including the evictions of the backend if it is
+// a MemoryCache.
+func (c *responseCache) useMetrics(m *cacheMetrics) {
+	c.metrics = m
+	if mc, ok := c.backend.(*MemoryCache); ok {
+		mc.mu.Lock()
+		mc.onEvict = m.evictions.Inc
+		mc.mu.Unlock()
+	}
+}
+
+// len returns the number of entries held in memory: the cached errors and, if the backend is a MemoryCache, the
+// cached responses.
+func (c *responseCache) len() int {
+	n := 0
+	if mc, ok := c.backend.(*MemoryCache); ok {
+		n = mc.Len()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return n + len(c.negative)
+}
+
+// setErr caches the error of the request for ttl.
+func (c *responseCache) setErr(req Request, err error, ttl time.Duration) {
+	key := c.keyFn(req)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.negative[key] = cacheEntry{
+		err:       err,
+		expiresAt: c.clock.Now().Add(ttl),
+	}
+}
+
+// WithNegativeCache is an option that caches the errors of the work for ttl too, so that a flood of identical
+// requests known to fail doesn't hammer the downstream. Only the errors for which shouldCache returns true are
+// cached. A nil shouldCache caches every error except the context cancellations and timeouts, which say nothing
+// about the request. A cached error is returned without calling the work, and it is replaced by the fallback
+// response if there is a fallback (see WithFallback). It has no effect without WithCache.
+func WithNegativeCache(ttl time.Duration, shouldCache func(error) bool) Option {
+	return func(s *Service) {
+		if shouldCache == nil {
+			shouldCache = isNotContextErr
+		}
+		s.negativeTTL = ttl
+		s.negativeShouldCache = shouldCache
+	}
+}
+
+// isNotContextErr reports whether err is not caused by the cancellation or the deadline of a context.
+func isNotContextErr(err error) bool {
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
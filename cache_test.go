@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test case for identical requests. The second request is served from the cache without calling the work.
+func TestService_Serve_CacheHit(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: "response " + req.Data}, nil
+	}, WithCache(time.Minute, nil))
+
+	for i := 0; i < 2; i++ {
+		response, err := srv.Serve(context.Background(), Request{Data: "a"})
+
+		if err != nil {
+			t.Errorf("Serve() should not return an error, got %v", err)
+		}
+		wantResp := Response{Data: "response a"}
+		if !reflect.DeepEqual(response, wantResp) {
+			t.Errorf("Serve() got response %v, wanted %v", response, wantResp)
+		}
+	}
+
+	if srv.Attempts() != 1 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 1)
+	}
+	wantStats := CacheStats{Hits: 1, Misses: 1}
+	if stats := srv.CacheStats(); stats != wantStats {
+		t.Errorf("CacheStats() got %+v, wanted %+v", stats, wantStats)
+	}
+}
+
+// Test case for requests with different keys. Every request calls the work.
+func TestService_Serve_CacheKey(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: "response " + req.Data}, nil
+	}, WithCache(time.Minute, func(req Request) string {
+		return req.Data
+	}))
+
+	srv.Serve(context.Background(), Request{Data: "a"})
+	response, _ := srv.Serve(context.Background(), Request{Data: "b"})
+
+	wantResp := Response{Data: "response b"}
+	if !reflect.DeepEqual(response, wantResp) {
+		t.Errorf("Serve() got response %v, wanted %v", response, wantResp)
+	}
+	if srv.Attempts() != 2 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+	}
+}
+
+// Test case for an expired entry. The entry is evicted and the work is called again.
+func TestService_Serve_CacheExpired(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: "success"}, nil
+	}, WithCache(20*time.Millisecond, nil))
+
+	srv.Serve(context.Background(), Request{})
+	time.Sleep(30 * time.Millisecond)
+	srv.Serve(context.Background(), Request{})
+
+	if srv.Attempts() != 2 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+	}
+	wantStats := CacheStats{Hits: 0, Misses: 2}
+	if stats := srv.CacheStats(); stats != wantStats {
+		t.Errorf("CacheStats() got %+v, wanted %+v", stats, wantStats)
+	}
+}
+
+// Test case for a failing work. Errors are never cached.
+func TestService_Serve_CacheError(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, errors.New("error")
+	}, WithCache(time.Minute, nil))
+
+	for i := 0; i < 2; i++ {
+		if _, err := srv.Serve(context.Background(), Request{}); err == nil {
+			t.Errorf("Serve() should return an error")
+		}
+	}
+
+	if srv.Attempts() != 2 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+	}
+}
+
+// Test case for concurrent requests. The cache is safe for concurrent use.
+func TestService_Serve_CacheConcurrent(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: "response " + req.Data}, nil
+	}, WithCache(time.Minute, nil))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			srv.Serve(context.Background(), Request{Data: string(rune('a' + i%5))})
+		}(i)
+	}
+	wg.Wait()
+
+	if stats := srv.CacheStats(); stats.Hits+stats.Misses != 50 {
+		t.Errorf("CacheStats() got %+v, wanted %d lookups", stats, 50)
+	}
+}
+
+// Test case for negative caching: a successful response and a cached error are both served without calling the work,
+// until the error expires.
+func TestService_Serve_NegativeCache(t *testing.T) {
+	wantErr := errors.New("not found")
+	clock := NewFakeClock(time.Now())
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		if req.Data == "bad" {
+			return Response{}, wantErr
+		}
+		return Response{Data: "response " + req.Data}, nil
+	}, WithCache(time.Minute, nil), WithNegativeCache(time.Second, nil), WithClock(clock))
+
+	for i := 0; i < 2; i++ {
+		if res, err := srv.Serve(context.Background(), Request{Data: "good"}); err != nil || res.Data != "response good" {
+			t.Errorf("Serve() got (%v, %v), wanted (%v, %v)", res, err, Response{Data: "response good"}, nil)
+		}
+		if _, err := srv.Serve(context.Background(), Request{Data: "bad"}); err != wantErr {
+			t.Errorf("Serve() got err %v, wanted %v", err, wantErr)
+		}
+	}
+	if srv.Attempts() != 2 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+	}
+
+	// The cached error expires long before the cached response.
+	clock.Advance(time.Second)
+	if _, err := srv.Serve(context.Background(), Request{Data: "bad"}); err != wantErr {
+		t.Errorf("Serve() got err %v, wanted %v", err, wantErr)
+	}
+	if _, err := srv.Serve(context.Background(), Request{Data: "good"}); err != nil {
+		t.Errorf("Serve() got err %v, wanted %v", err, nil)
+	}
+	if srv.Attempts() != 3 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 3)
+	}
+}
+
+// Test case for the errors that are not negatively cached: the errors rejected by shouldCache, and by default the
+// context errors
+func TestService_Serve_NegativeCacheSkipped(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		shouldCache func(error) bool
+	}{
+		{name: "rejected by shouldCache", err: errors.New("error"), shouldCache: func(error) bool { return false }},
+		{name: "deadline exceeded", err: context.DeadlineExceeded},
+		{name: "cancelled", err: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+				return Response{}, tt.err
+			}, WithCache(time.Minute, nil), WithNegativeCache(time.Minute, tt.shouldCache))
+
+			for i := 0; i < 2; i++ {
+				if _, err := srv.Serve(context.Background(), Request{}); err != tt.err {
+					t.Errorf("Serve() got err %v, wanted %v", err, tt.err)
+				}
+			}
+			if srv.Attempts() != 2 {
+				t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+			}
+		})
+	}
+}
+
+// fakeCache is a Cache backed by a map, failing every call with err if it is not nil
+type fakeCache struct {
+	mu      sync.Mutex
+	entries map[string]Response
+	ttls    map[string]time.Duration
+	err     error
+}
+
+func newFakeCache(err error) *fakeCache {
+	return &fakeCache{entries: make(map[string]Response), ttls: make(map[string]time.Duration), err: err}
+}
+
+func (c *fakeCache) Get(ctx context.Context, key string) (Response, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return Response{}, false, c.err
+	}
+	res, ok := c.entries[key]
+
+	return res, ok, nil
+}
+
+func (c *fakeCache) Set(ctx context.Context, key string, res Response, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	c.entries[key] = res
+	c.ttls[key] = ttl
+
+	return nil
+}
+
+// Test case for a custom cache backend. The response is stored in the backend with the ttl, and the second request
+// is served from it.
+func TestService_Serve_CacheBackend(t *testing.T) {
+	backend := newFakeCache(nil)
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: "response " + req.Data}, nil
+	}, WithCache(time.Minute, nil), WithCacheBackend(backend))
+
+	for i := 0; i < 2; i++ {
+		res, err := srv.Serve(context.Background(), Request{Data: "a"})
+		if err != nil || res.Data != "response a" {
+			t.Errorf("Serve() got (%v, %v), wanted (%v, %v)", res, err, Response{Data: "response a"}, nil)
+		}
+	}
+
+	if srv.Attempts() != 1 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 1)
+	}
+	key := Fingerprint(Request{Data: "a"})
+	if backend.entries[key].Data != "response a" || backend.ttls[key] != time.Minute {
+		t.Errorf("backend got entry %v for %v, wanted %v for %v", backend.entries[key], backend.ttls[key], Response{Data: "response a"}, time.Minute)
+	}
+	wantStats := CacheStats{Hits: 1, Misses: 1}
+	if stats := srv.CacheStats(); stats != wantStats {
+		t.Errorf("CacheStats() got %+v, wanted %+v", stats, wantStats)
+	}
+}
+
+// Test case for a failing cache backend. The work serves the request, unless the cache is strict.
+func TestService_Serve_CacheBackendError(t *testing.T) {
+	backendErr := errors.New("connection refused")
+	tests := []struct {
+		name     string
+		strict   bool
+		wantErr  error
+		attempts int
+	}{
+		{name: "lenient", strict: false, wantErr: nil, attempts: 2},
+		{name: "strict", strict: true, wantErr: backendErr, attempts: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServiceWithOptions((&TestService{}).Serve,
+				WithCache(time.Minute, nil), WithCacheBackend(newFakeCache(backendErr)), WithCacheStrictness(tt.strict))
+
+			for i := 0; i < 2; i++ {
+				if _, err := srv.Serve(context.Background(), Request{}); !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+					t.Errorf("Serve() got err %v, wanted %v", err, tt.wantErr)
+				}
+			}
+			if srv.Attempts() != tt.attempts {
+				t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), tt.attempts)
+			}
+		})
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	c := NewMemoryCache()
+	c.clock = clock
+	ctx := context.Background()
+
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Errorf("Get() should miss an empty cache")
+	}
+	c.Set(ctx, "a", Response{Data: "a"}, time.Second)
+	if res, ok, err := c.Get(ctx, "a"); !ok || err != nil || res.Data != "a" {
+		t.Errorf("Get() got (%v, %v, %v), wanted (%v, %v, %v)", res, ok, err, Response{Data: "a"}, true, nil)
+	}
+	clock.Advance(time.Second)
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Errorf("Get() should miss an expired entry")
+	}
+}
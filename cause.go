@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// contextErr returns the error of a done context, carrying the cause of the cancellation set with
+// context.WithCancelCause (or the similar functions) when there is one, so that the callers get the richer reason.
+// errors.Is reports true for both the cause and the error of the context, e.g. context.Canceled.
+// It returns ctx.Err() unchanged when there is no cause, and the cause unchanged when it already wraps ctx.Err().
+func contextErr(ctx context.Context) error {
+	err := ctx.Err()
+	cause := context.Cause(ctx)
+	if err == nil || cause == nil || cause == err {
+		return err
+	}
+	if errors.Is(cause, err) {
+		return cause
+	}
+
+	return fmt.Errorf("%w: %w", cause, err)
+}
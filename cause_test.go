@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Test case for a context cancelled with a cause. The cause propagates through Serve, and so does the context error.
+func TestService_Serve_CancelCause(t *testing.T) {
+	cause := errors.New("client went away")
+	tests := []struct {
+		name string
+		// delays and errs make the first call fail immediately, so that the retry waits for the backoff.
+		delays []time.Duration
+		errs   []error
+		opts   []Option
+	}{
+		{name: "no options"},
+		{name: "retry backoff", delays: []time.Duration{0}, errs: []error{errors.New("error")}, opts: []Option{WithRetry(2, time.Minute)}},
+		{name: "hedging", opts: []Option{WithHedging(time.Millisecond, 1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := &TestService{DelayReponse: time.Minute, Delays: tt.delays, Errs: tt.errs}
+			srv := NewServiceWithOptions(ts.Serve, tt.opts...)
+			ctx, cancel := context.WithCancelCause(context.Background())
+			time.AfterFunc(20*time.Millisecond, func() { cancel(cause) })
+
+			_, err := srv.Serve(ctx, Request{})
+
+			if !errors.Is(err, cause) {
+				t.Errorf("Serve() got err %v, wanted %v", err, cause)
+			}
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Serve() got err %v, wanted %v", err, context.Canceled)
+			}
+		})
+	}
+}
+
+// Test case for the cause recorded by the TestService
+func TestTestService_CtxCause(t *testing.T) {
+	cause := errors.New("cause")
+	ts := &TestService{DelayReponse: time.Minute}
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(cause)
+
+	_, err := ts.Serve(ctx, Request{})
+
+	if err != context.Canceled {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.Canceled)
+	}
+	if got := ts.Snapshot().CtxCause; got != cause {
+		t.Errorf("got CtxCause %v, wanted %v", got, cause)
+	}
+}
+
+func TestContextErr(t *testing.T) {
+	cause := errors.New("cause")
+	wrapping := fmt.Errorf("cause: %w", context.Canceled)
+	tests := []struct {
+		name   string
+		cancel func(context.CancelCauseFunc)
+		want   []error
+	}{
+		{name: "not done", cancel: func(context.CancelCauseFunc) {}, want: nil},
+		{name: "no cause", cancel: func(c context.CancelCauseFunc) { c(nil) }, want: []error{context.Canceled}},
+		{name: "cause", cancel: func(c context.CancelCauseFunc) { c(cause) }, want: []error{cause, context.Canceled}},
+		{name: "wrapping cause", cancel: func(c context.CancelCauseFunc) { c(wrapping) }, want: []error{wrapping, context.Canceled}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancelCause(context.Background())
+			defer cancel(nil)
+			tt.cancel(cancel)
+
+			err := contextErr(ctx)
+
+			if tt.want == nil && err != nil {
+				t.Errorf("contextErr() got %v, wanted %v", err, nil)
+			}
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("contextErr() got %v, wanted %v", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+// resultChan returns a channel receiving the outcome of the given number of goroutines, e.g. the copies of the work
+// launched by hedging or the servers called by Any.
+//
+// The channel is buffered with a slot for every sender, so no send ever blocks, even after the receiver stopped
+// listening because the context got cancelled, the first result won or Serve returned. An unbuffered channel, or a
+// buffer smaller than the number of senders, would leave the goroutines of the late senders blocked forever, leaking
+// them along with everything they reference. Every feature launching goroutines must get its channel here, declaring
+// how many of them may send.
+func resultChan(senders int) chan result {
+	return typedResultChan[Response](senders)
+}
+
+// typedResultChan is the generic variant of resultChan, e.g. for the typed responses of GenericService.
+func typedResultChan[Res any](senders int) chan typedResult[Res] {
+	if senders < 1 {
+		senders = 1
+	}
+
+	return make(chan typedResult[Res], senders)
+}
+
+// WithResultPool is an option that keeps a pool of size channels receiving the outcome of the work, allocated up
+// front, for the Services serving lots of requests, e.g. fanning them out with ServeAsync. By default the channels
+// are reused through a sync.Pool shared by all the Services, which is emptied by every garbage collection, so under
+// a steady load a part of the calls allocates a new channel anyway. A pool bigger than the number of concurrent
+// calls doesn't save anything more. A size lower or equal to 0 keeps the default.
+func WithResultPool(size int) Option {
+	return func(s *Service) {
+		s.resultPool = nil
+		if size > 0 {
+			s.resultPool = newResultPool(size)
+		}
+	}
+}
+
+// resultPool is a fixed size pool of channels with a single sender, safe for concurrent use.
+type resultPool struct {
+	// free holds the channels available for reuse.
+	free chan chan result
+}
+
+// newResultPool creates a resultPool filled with size channels.
+func newResultPool(size int) *resultPool {
+	p := &resultPool{free: make(chan chan result, size)}
+	for range size {
+		p.free <- resultChan(1)
+	}
+
+	return p
+}
+
+// get returns a channel of the pool, or a new one if the pool is empty.
+func (p *resultPool) get() chan result {
+	select {
+	case ch := <-p.free:
+		return ch
+	default:
+		return resultChan(1)
+	}
+}
+
+// put returns the channel to the pool, unless the pool is full.
+func (p *resultPool) put(ch chan result) {
+	select {
+	case p.free <- ch:
+	default:
+	}
+}
+
+// getResultChan returns a channel receiving the outcome of the work, from the pool of the Service.
+func (s *Service) getResultChan() chan result {
+	if s.resultPool != nil {
+		return s.resultPool.get()
+	}
+
+	return resultChans.Get().(chan result)
+}
+
+// putResultChan returns the channel to the pool of the Service. It must be called only once the single sender of the
+// channel has sent its result, so that nothing is sent on the channel after its reuse. The channel is drained first,
+// so that a reused channel never delivers a stale result.
+func (s *Service) putResultChan(ch chan result) {
+	for len(ch) > 0 {
+		<-ch
+	}
+	if s.resultPool != nil {
+		s.resultPool.put(ch)
+		return
+	}
+	resultChans.Put(ch)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResultChan(t *testing.T) {
+	tests := []struct {
+		name    string
+		senders int
+		want    int
+	}{
+		{name: "no sender", senders: 0, want: 1},
+		{name: "single sender", senders: 1, want: 1},
+		{name: "many senders", senders: 4, want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cap(resultChan(tt.senders)); got != tt.want {
+				t.Errorf("resultChan() got capacity %d, wanted %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test case for concurrent calls abandoning their hedged copies on timeout. Every copy sends its result after the
+// calls returned, and no goroutine is left blocked. Run it with the race detector.
+func TestService_Serve_HedgingNoLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+	// The work ignores the context, so every copy sends its result after the timeout.
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		time.Sleep(50 * time.Millisecond)
+		return Response{Data: "slow"}, nil
+	}, WithHedging(2*time.Millisecond, 3))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			if _, err := srv.Serve(ctx, Request{}); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := srv.Close(context.Background()); err != nil {
+		t.Fatalf("Close() got err %v, wanted %v", err, nil)
+	}
+	waitFor(t, func() bool { return runtime.NumGoroutine() <= before })
+}
+
+// Test case for ServeAsync with a result pool much smaller than the number of requests, half of them abandoned by
+// their callers. Every Future gets the result of its own request, never a stale one. Run it with the race detector.
+func TestService_ServeAsync_ResultPool(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		time.Sleep(5 * time.Millisecond)
+		return Response{Data: req.Data}, nil
+	}, WithResultPool(4))
+
+	futures := make([]*Future, 200)
+	for i := range futures {
+		ctx := context.Background()
+		if i%2 == 1 {
+			// The work ignores the context, so it sends its result after the call has returned.
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Millisecond)
+			defer cancel()
+		}
+		futures[i] = srv.ServeAsync(ctx, Request{Data: fmt.Sprint(i)})
+	}
+
+	for i, f := range futures {
+		res, err := f.Wait()
+		// An abandoned request may still get its result, if it arrives before Serve sees the deadline.
+		if i%2 == 1 && err != nil {
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Wait() of request %d got err %v, wanted %v", i, err, context.DeadlineExceeded)
+			}
+			continue
+		}
+		if err != nil || res.Data != fmt.Sprint(i) {
+			t.Errorf("Wait() of request %d got %v and err %v, wanted %v", i, res, err, Response{Data: fmt.Sprint(i)})
+		}
+	}
+	if err := srv.Close(context.Background()); err != nil {
+		t.Fatalf("Close() got err %v, wanted %v", err, nil)
+	}
+}
+
+// Test case for a channel returned to the result pool with a result in it. It is reused empty.
+func TestService_putResultChan_Drains(t *testing.T) {
+	srv := NewServiceWithOptions(noopWork, WithResultPool(1))
+	ch := srv.getResultChan()
+	ch <- result{res: Response{Data: "stale"}}
+
+	srv.putResultChan(ch)
+
+	reused := srv.getResultChan()
+	if reused != ch || len(reused) != 0 {
+		t.Errorf("getResultChan() got a channel with %d results, wanted the pooled channel empty", len(reused))
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is the error returned by Serve when the circuit breaker is open and the work is not called.
+var ErrCircuitOpen = errors.New("service: circuit breaker is open")
+
+// States of the CircuitBreaker, as returned by State.
+const (
+	// CircuitClosed is the state in which the work is called normally.
+	CircuitClosed = "closed"
+	// CircuitOpen is the state in which the work is not called and ErrCircuitOpen is returned instead.
+	CircuitOpen = "open"
+	// CircuitHalfOpen is the state in which a single trial call is allowed in order to check if the work has recovered.
+	CircuitHalfOpen = "half-open"
+)
+
+// WithCircuitBreaker is an option that stops calling a failing work, in order to stop hammering a downstream
+// dependency that is already in trouble.
+// After failureThreshold consecutive failures of the work the circuit breaker opens, and every call returns
+// ErrCircuitOpen without calling the work. After openDuration the circuit breaker becomes half-open and allows
+// a single trial call. If the trial call succeeds the circuit breaker closes again, otherwise it opens for
+// another openDuration.
+// The circuit breaker guards every attempt of the work. ErrCircuitOpen is never retried (see WithRetry).
+func WithCircuitBreaker(failureThreshold int, openDuration time.Duration) Option {
+	return func(s *Service) {
+		s.breaker = &CircuitBreaker{
+			failureThreshold: failureThreshold,
+			openDuration:     openDuration,
+			state:            CircuitClosed,
+			clock:            realClock{},
+		}
+	}
+}
+
+// CircuitBreaker keeps track of the consecutive failures of the work and decides if the work can be called.
+// It is safe for concurrent use, since Serve may be called from many goroutines.
+type CircuitBreaker struct {
+	failureThreshold int
+	openDuration     time.Duration
+	// clock is the source of time, set to the clock of the Service.
+	clock Clock
+
+	// mu guards the fields below.
+	mu sync.Mutex
+	// state is the current state, without taking into account that the open duration may have passed.
+	state string
+	// failures is the number of consecutive failures in the closed state.
+	failures int
+	// openedAt is the time the circuit breaker opened.
+	openedAt time.Time
+	// trial is true while the trial call of the half-open state is in progress.
+	trial bool
+}
+
+// CircuitBreaker returns the circuit breaker of the Service, or nil if WithCircuitBreaker was not used.
+func (s *Service) CircuitBreaker() *CircuitBreaker {
+	return s.breaker
+}
+
+// State returns the current state of the circuit breaker: CircuitClosed, CircuitOpen or CircuitHalfOpen.
+func (cb *CircuitBreaker) State() string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.halfOpenIfExpired()
+
+	return cb.state
+}
+
+// allow reports if the work can be called, returning ErrCircuitOpen if it can't.
+// trial is true if the call is the trial call of the half-open state.
+func (cb *CircuitBreaker) allow() (trial bool, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.halfOpenIfExpired()
+
+	switch cb.state {
+	case CircuitClosed:
+		return false, nil
+	case CircuitHalfOpen:
+		// Only a single trial call is allowed.
+		if cb.trial {
+			return false, ErrCircuitOpen
+		}
+		cb.trial = true
+		return true, nil
+	default:
+		return false, ErrCircuitOpen
+	}
+}
+
+// record records the outcome of a call allowed by allow.
+func (cb *CircuitBreaker) record(trial bool, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if trial {
+		cb.trial = false
+		if err != nil {
+			cb.open()
+			return
+		}
+		cb.state = CircuitClosed
+		cb.failures = 0
+		return
+	}
+
+	// Ignore the outcome of calls that were allowed before the circuit breaker opened.
+	if cb.state != CircuitClosed {
+		return
+	}
+	if err == nil {
+		cb.failures = 0
+		return
+	}
+	cb.failures++
+	if cb.failures >= cb.failureThreshold {
+		cb.open()
+	}
+}
+
+// open moves the circuit breaker to the open state. It must be called with mu held.
+func (cb *CircuitBreaker) open() {
+	cb.state = CircuitOpen
+	cb.failures = 0
+	cb.openedAt = cb.clock.Now()
+}
+
+// halfOpenIfExpired moves the circuit breaker to the half-open state if the open duration has passed.
+// It must be called with mu held.
+func (cb *CircuitBreaker) halfOpenIfExpired() {
+	if cb.state == CircuitOpen && cb.clock.Now().Sub(cb.openedAt) >= cb.openDuration {
+		cb.state = CircuitHalfOpen
+	}
+}
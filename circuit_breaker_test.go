@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test case for the circuit breaker lifecycle: closed -> open -> half-open -> closed.
+func TestService_Serve_CircuitBreaker(t *testing.T) {
+	var mu sync.Mutex
+	fail := true
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			return Response{}, errors.New("error")
+		}
+		return Response{Data: "success"}, nil
+	}, WithCircuitBreaker(2, 50*time.Millisecond))
+	cb := srv.CircuitBreaker()
+
+	for i := 0; i < 2; i++ {
+		if _, err := srv.Serve(context.Background(), Request{}); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Serve() got err %v, wanted the work error", err)
+		}
+	}
+	if cb.State() != CircuitOpen {
+		t.Errorf("State() got %v, wanted %v", cb.State(), CircuitOpen)
+	}
+
+	// The work is not called while the circuit breaker is open.
+	if _, err := srv.Serve(context.Background(), Request{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Serve() got err %v, wanted %v", err, ErrCircuitOpen)
+	}
+	if srv.Attempts() != 2 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if cb.State() != CircuitHalfOpen {
+		t.Errorf("State() got %v, wanted %v", cb.State(), CircuitHalfOpen)
+	}
+
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+	if cb.State() != CircuitClosed {
+		t.Errorf("State() got %v, wanted %v", cb.State(), CircuitClosed)
+	}
+}
+
+// Test case for a failed trial call. The circuit breaker opens again.
+func TestService_Serve_CircuitBreakerTrialFails(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, errors.New("error")
+	}, WithCircuitBreaker(1, 50*time.Millisecond))
+	cb := srv.CircuitBreaker()
+
+	srv.Serve(context.Background(), Request{})
+	time.Sleep(60 * time.Millisecond)
+	srv.Serve(context.Background(), Request{})
+
+	if cb.State() != CircuitOpen {
+		t.Errorf("State() got %v, wanted %v", cb.State(), CircuitOpen)
+	}
+	if srv.Attempts() != 2 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+	}
+}
+
+// Test case for concurrent calls in the half-open state. Only a single trial call reaches the work.
+func TestService_Serve_CircuitBreakerSingleTrial(t *testing.T) {
+	release := make(chan struct{})
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		if req.Data == "fail" {
+			return Response{}, errors.New("error")
+		}
+		<-release
+		return Response{Data: "success"}, nil
+	}, WithCircuitBreaker(1, 10*time.Millisecond))
+
+	srv.Serve(context.Background(), Request{Data: "fail"})
+	time.Sleep(20 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := srv.Serve(context.Background(), Request{})
+			errs <- err
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		} else if !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Serve() got err %v, wanted %v", err, ErrCircuitOpen)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("got %d successful trial calls, wanted %d", succeeded, 1)
+	}
+	if srv.Attempts() != 2 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+	}
+}
+
+// Test case for the open circuit breaker with retries. ErrCircuitOpen is not retried.
+func TestService_Serve_CircuitBreakerNotRetried(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, errors.New("error")
+	}, WithRetry(5, 0), WithCircuitBreaker(2, time.Minute))
+
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Serve() got err %v, wanted %v", err, ErrCircuitOpen)
+	}
+	if srv.Attempts() != 2 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+// ErrorClassifier returns the class of an error of Serve, e.g. "timeout", "validation" or "downstream_5xx", used as
+// the outcome of the failed requests in the metrics and the log events. Since the classes become metric label
+// values, a classifier should return a small, fixed set of classes.
+type ErrorClassifier func(err error) string
+
+// DefaultErrorClassifier is the ErrorClassifier used when WithErrorClassifier is not used. It classifies the errors
+// as OutcomeTimeout, OutcomeCancelled or OutcomeError. Custom classifiers can fall back to it for the errors of
+// unknown kind.
+func DefaultErrorClassifier(err error) string {
+	return outcome(err)
+}
+
+// WithErrorClassifier is an option that sets the classifier of the errors of Serve. The class of the error becomes
+// the outcome label of <prefix>_serve_total (see WithMetrics) and the Outcome of the LogEvent (see WithLogger).
+// The successful requests are always classified as OutcomeSuccess. A nil classifier restores the default one.
+func WithErrorClassifier(classifier ErrorClassifier) Option {
+	return func(s *Service) {
+		s.classifier = classifier
+	}
+}
+
+// classify returns the outcome of a request served with the given error, using the classifier of the Service.
+func (s *Service) classify(err error) string {
+	if err == nil {
+		return OutcomeSuccess
+	}
+	if s.classifier == nil {
+		return DefaultErrorClassifier(err)
+	}
+
+	return s.classifier(err)
+}
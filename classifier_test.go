@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// Test case for the default classifier of the errors
+func TestDefaultErrorClassifier(t *testing.T) {
+	tests := []struct {
+		err   error
+		class string
+	}{
+		{err: context.DeadlineExceeded, class: OutcomeTimeout},
+		{err: context.Canceled, class: OutcomeCancelled},
+		{err: errors.New("error"), class: OutcomeError},
+	}
+	for _, tt := range tests {
+		if class := DefaultErrorClassifier(tt.err); class != tt.class {
+			t.Errorf("DefaultErrorClassifier(%v) got %q, wanted %q", tt.err, class, tt.class)
+		}
+	}
+}
+
+// Test case for a custom classifier feeding the metrics and the log events
+func TestService_Serve_ErrorClassifier(t *testing.T) {
+	downstreamErr := errors.New("downstream 503")
+	registry := prometheus.NewRegistry()
+	var events []LogEvent
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		switch req.Data {
+		case "downstream":
+			return Response{}, downstreamErr
+		case "slow":
+			<-ctx.Done()
+			return Response{}, ctx.Err()
+		}
+		return Response{}, nil
+	},
+		WithErrorClassifier(func(err error) string {
+			if errors.Is(err, downstreamErr) {
+				return "downstream_5xx"
+			}
+			return DefaultErrorClassifier(err)
+		}),
+		WithMetrics(registry),
+		WithLogger(func(ctx context.Context, event LogEvent) {
+			events = append(events, event)
+		}),
+	)
+	defer srv.UnregisterMetrics()
+
+	srv.Serve(context.Background(), Request{Data: "downstream"})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	srv.Serve(ctx, Request{Data: "slow"})
+	srv.Serve(context.Background(), Request{})
+
+	wanted := []string{"downstream_5xx", OutcomeTimeout, OutcomeSuccess}
+	for i, class := range wanted {
+		if got := testutil.ToFloat64(srv.metrics.served.WithLabelValues(class)); got != 1 {
+			t.Errorf("service_serve_total{outcome=%q} got %v, wanted %v", class, got, 1)
+		}
+		if events[i].Outcome != class {
+			t.Errorf("got event outcome %q, wanted %q", events[i].Outcome, class)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of the Service. All the internal timing (backoff, hedging, rate limiting, circuit
+// breaking, caching and the measured durations) goes through it, so that tests can control the time with a
+// FakeClock (see WithClock). The deadlines of the contexts are not affected, since they are handled by the context
+// package in wall-clock time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+	// NewTimer creates a Timer that sends the current time on its channel after at least the duration.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a timer created by a Clock, like time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is sent when the timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It returns false if the timer has already fired or been stopped.
+	Stop() bool
+	// Reset changes the timer to fire after the duration. It returns true if the timer had been active.
+	Reset(d time.Duration) bool
+}
+
+// WithClock is an option that sets the source of time of the Service. The default is the wall clock.
+func WithClock(clock Clock) Option {
+	return func(s *Service) {
+		s.clock = clock
+	}
+}
+
+// realClock is the Clock of the wall time, backed by the time package.
+type realClock struct{}
+
+// Now returns time.Now().
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// After returns time.After(d).
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// NewTimer returns a Timer backed by time.NewTimer(d).
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+// realTimer is a Timer backed by a time.Timer.
+type realTimer struct {
+	t *time.Timer
+}
+
+// C returns the channel of the time.Timer.
+func (t realTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+// Stop stops the time.Timer.
+func (t realTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// Reset resets the time.Timer.
+func (t realTimer) Reset(d time.Duration) bool {
+	return t.t.Reset(d)
+}
+
+// FakeClock is a Clock for tests that moves only when Advance is called, so that time dependent features (backoff,
+// circuit breaking, caching etc) can be tested instantly and deterministically. It is safe for concurrent use.
+type FakeClock struct {
+	mu sync.Mutex
+	// cond is signalled every time a timer is added or removed.
+	cond *sync.Cond
+	// now is the current time of the clock.
+	now time.Time
+	// timers are the timers that have not fired or been stopped yet.
+	timers []*fakeTimer
+}
+
+// NewFakeClock creates a FakeClock starting at the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+
+	return c
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// After returns the channel of a new timer.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer creates a Timer that fires when the clock is advanced by at least the duration. A Timer with a duration
+// lower or equal to zero fires immediately.
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1)}
+	t.Reset(d)
+
+	return t
+}
+
+// Advance moves the clock forward by the duration, firing all the timers that expire in the meantime.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.fire(c.now)
+	}
+	// Clear the tail, so that the fired timers can be garbage collected.
+	for i := len(pending); i < len(c.timers); i++ {
+		c.timers[i] = nil
+	}
+	c.timers = pending
+	c.cond.Broadcast()
+}
+
+// BlockUntil blocks until there are at least n timers waiting for the clock to advance, e.g. a goroutine waiting
+// between two retries. It is used for advancing the clock only after the code under test started waiting.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// fakeTimer is a Timer of a FakeClock.
+type fakeTimer struct {
+	clock *FakeClock
+	// c receives the time when the timer fires. It is buffered, so firing never blocks.
+	c chan time.Time
+	// deadline is the time the timer fires. It is guarded by the mutex of the clock.
+	deadline time.Time
+}
+
+// C returns the channel of the timer.
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop stops the timer.
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	return t.remove()
+}
+
+// Reset stops the timer and schedules it to fire after the duration.
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	active := t.remove()
+	if d <= 0 {
+		t.fire(c.now)
+		return active
+	}
+	t.deadline = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+
+	return active
+}
+
+// fire sends the time on the channel of the timer, unless a previous time has not been received yet.
+func (t *fakeTimer) fire(now time.Time) {
+	select {
+	case t.c <- now:
+	default:
+	}
+}
+
+// remove removes the timer from the clock, reporting if it was waiting. It must be called with the mutex of the
+// clock held.
+func (t *fakeTimer) remove() bool {
+	c := t.clock
+	for i, other := range c.timers {
+		if other == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+
+	return false
+}
+
+// useClock hands the clock of the Service over to the parts created by the options. It is called after all the
+// options are applied, since WithClock may come after the options creating the parts.
+func (s *Service) useClock() {
+	if s.breaker != nil {
+		s.breaker.clock = s.clock
+	}
+	if s.limiter != nil {
+		s.limiter.setClock(s.clock)
+	}
+	if s.keyedLimiter != nil {
+		s.keyedLimiter.setClock(s.clock)
+	}
+	if s.cache != nil {
+		s.cache.clock = s.clock
+		if mc, ok := s.cache.backend.(*MemoryCache); ok {
+			mc.clock = s.clock
+		}
+	}
+	if s.retryBudget != nil && s.retryBudget.reserve != nil {
+		s.retryBudget.reserve.setClock(s.clock)
+	}
+	if s.shedder != nil {
+		s.shedder.clock = s.clock
+	}
+	if s.coalescer != nil {
+		s.coalescer.clock = s.clock
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Test case for the timers of the fake clock firing only when the clock is advanced past their deadline
+func TestFakeClock_Advance(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	short := clock.NewTimer(time.Second)
+	long := clock.After(time.Minute)
+
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case <-short.C():
+		t.Fatalf("the timer fired before its deadline")
+	default:
+	}
+
+	clock.Advance(time.Millisecond)
+	select {
+	case now := <-short.C():
+		if !now.Equal(start.Add(time.Second)) {
+			t.Errorf("the timer got time %v, wanted %v", now, start.Add(time.Second))
+		}
+	default:
+		t.Errorf("the timer did not fire at its deadline")
+	}
+	select {
+	case <-long:
+		t.Errorf("the timer fired before its deadline")
+	default:
+	}
+	if !clock.Now().Equal(start.Add(time.Second)) {
+		t.Errorf("Now() got %v, wanted %v", clock.Now(), start.Add(time.Second))
+	}
+}
+
+// Test case for a stopped timer of the fake clock never firing
+func TestFakeClock_Stop(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	timer := clock.NewTimer(time.Second)
+
+	if !timer.Stop() {
+		t.Errorf("Stop() got %v, wanted %v", false, true)
+	}
+	clock.Advance(time.Minute)
+
+	select {
+	case <-timer.C():
+		t.Errorf("the stopped timer fired")
+	default:
+	}
+	if timer.Stop() {
+		t.Errorf("Stop() got %v, wanted %v", true, false)
+	}
+}
+
+// Test case for BlockUntil returning once the timers are waiting
+func TestFakeClock_BlockUntil(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		clock.BlockUntil(2)
+	}()
+
+	clock.NewTimer(time.Second)
+	select {
+	case <-done:
+		t.Fatalf("BlockUntil() returned with a single timer")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	clock.NewTimer(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("BlockUntil() did not return with two timers")
+	}
+}
+
+// Test case for the retries waiting for the backoff on the fake clock, without any actual waiting
+func TestService_Serve_FakeClockRetry(t *testing.T) {
+	workErr := errors.New("error")
+	ts := &TestService{Errs: []error{workErr, workErr}, Res: Response{Data: "success"}}
+	clock := NewFakeClock(time.Now())
+	srv := NewServiceWithOptions(ts.Serve, WithRetry(3, time.Hour), WithClock(clock))
+
+	go func() {
+		for i := 0; i < 2; i++ {
+			clock.BlockUntil(1)
+			clock.Advance(time.Hour)
+		}
+	}()
+	response, err := srv.Serve(context.Background(), Request{})
+
+	if err != nil || !reflect.DeepEqual(response, Response{Data: "success"}) {
+		t.Errorf("Serve() got %v, %v, wanted %v, %v", response, err, Response{Data: "success"}, nil)
+	}
+	if srv.Attempts() != 3 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 3)
+	}
+}
+
+// Test case for the circuit breaker and the cache expiring on the fake clock
+func TestService_Serve_FakeClockExpiration(t *testing.T) {
+	workErr := errors.New("error")
+	ts := &TestService{Errs: []error{nil, workErr}, Res: Response{Data: "success"}}
+	clock := NewFakeClock(time.Now())
+	srv := NewServiceWithOptions(ts.Serve,
+		WithCache(time.Minute, nil),
+		WithCircuitBreaker(1, time.Hour),
+		WithClock(clock),
+	)
+
+	srv.Serve(context.Background(), Request{})
+	clock.Advance(time.Minute - time.Nanosecond)
+	if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+		t.Errorf("Serve() got err %v, wanted the cached response", err)
+	}
+
+	clock.Advance(time.Nanosecond)
+	if _, err := srv.Serve(context.Background(), Request{}); !errors.Is(err, workErr) {
+		t.Errorf("Serve() got err %v, wanted %v", err, workErr)
+	}
+	if state := srv.CircuitBreaker().State(); state != CircuitOpen {
+		t.Errorf("got circuit state %q, wanted %q", state, CircuitOpen)
+	}
+
+	clock.Advance(time.Hour)
+	if state := srv.CircuitBreaker().State(); state != CircuitHalfOpen {
+		t.Errorf("got circuit state %q, wanted %q", state, CircuitHalfOpen)
+	}
+}
+
+// Test case for the rate limiter refilling on the fake clock
+func TestService_Serve_FakeClockRateLimit(t *testing.T) {
+	ts := &TestService{}
+	clock := NewFakeClock(time.Now())
+	srv := NewServiceWithOptions(ts.Serve, WithRateLimit(1, 1), WithRateLimitMode(RateLimitReject), WithClock(clock))
+
+	srv.Serve(context.Background(), Request{})
+	if _, err := srv.Serve(context.Background(), Request{}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Serve() got err %v, wanted %v", err, ErrRateLimited)
+	}
+
+	clock.Advance(time.Second)
+	if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+		t.Errorf("Serve() got err %v, wanted %v", err, nil)
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WithCoalescing is an option that groups the requests arriving within window of each other in a single call of
+// batchFn, e.g. a multi-get of a key-value store, instead of calling the work for each one of them. The work of the
+// Service is not called at all: batchFn serves the requests and every caller gets the response and the error with
+// the index of its request, exactly like from the work, so the rest of the options (retries, timeouts etc) still
+// apply to every request.
+// A batch is dispatched window after its first request, or as soon as it has maxBatch requests if maxBatch is
+// greater than 0. A caller whose context is done before the dispatch is removed from the batch. The context of
+// batchFn carries the values of the context of the first request of the batch, and the latest deadline of the
+// requests if all of them have one, but it is not cancelled by any caller.
+// batchFn must return a response and an error for every request, aligned by index. The missing ones are replaced
+// by an error. A panic of batchFn is recovered like a panic of the work and returned to every caller of the batch.
+func WithCoalescing(window time.Duration, maxBatch int, batchFn func(ctx context.Context, reqs []Request) ([]Response, []error)) Option {
+	return func(s *Service) {
+		s.coalescer = &coalescer{
+			window:   window,
+			maxBatch: maxBatch,
+			batchFn:  batchFn,
+			recover:  s.recoverWork,
+			clock:    realClock{},
+		}
+		s.work = s.coalescer.serve
+	}
+}
+
+// coalescer groups the requests in batches, safe for concurrent use.
+type coalescer struct {
+	window   time.Duration
+	maxBatch int
+	batchFn  func(ctx context.Context, reqs []Request) ([]Response, []error)
+	// recover converts a panic of batchFn to an error.
+	recover func(err *error)
+	// clock is the source of time, set to the clock of the Service.
+	clock Clock
+
+	// mu guards pending and the batches.
+	mu sync.Mutex
+	// pending is the batch accepting requests, nil if there is none.
+	pending *batch
+}
+
+// batch is a group of requests dispatched together.
+type batch struct {
+	calls []*coalescedCall
+	// full is closed when the batch reaches the maximum size, so that it is dispatched before the window ends.
+	full chan struct{}
+}
+
+// coalescedCall is a request waiting in a batch.
+type coalescedCall struct {
+	ctx context.Context
+	req Request
+	// resultCh receives the outcome of the request. It is buffered, so the dispatch never blocks on a caller that
+	// has gone away.
+	resultCh chan result
+	// removed is true if the caller has gone away before the dispatch.
+	removed bool
+}
+
+// serve adds the request to the pending batch and waits for its outcome, or until the context is done.
+func (c *coalescer) serve(ctx context.Context, req Request) (Response, error) {
+	call := &coalescedCall{ctx: ctx, req: req, resultCh: resultChan(1)}
+
+	c.mu.Lock()
+	b := c.pending
+	if b == nil {
+		b = &batch{full: make(chan struct{})}
+		c.pending = b
+		go c.dispatchAfter(b, c.clock.NewTimer(c.window))
+	}
+	b.calls = append(b.calls, call)
+	if c.maxBatch > 0 && len(b.calls) >= c.maxBatch {
+		// Stop accepting requests in this batch and dispatch it right away.
+		c.pending = nil
+		close(b.full)
+	}
+	c.mu.Unlock()
+
+	select {
+	case r := <-call.resultCh:
+		return r.res, r.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		call.removed = true
+		c.mu.Unlock()
+		return Response{}, contextErr(ctx)
+	}
+}
+
+// dispatchAfter dispatches the batch when the timer fires or when the batch is full, whatever happens first.
+func (c *coalescer) dispatchAfter(b *batch, timer Timer) {
+	select {
+	case <-timer.C():
+	case <-b.full:
+		timer.Stop()
+	}
+
+	c.mu.Lock()
+	if c.pending == b {
+		c.pending = nil
+	}
+	calls := make([]*coalescedCall, 0, len(b.calls))
+	for _, call := range b.calls {
+		if !call.removed {
+			calls = append(calls, call)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(calls) == 0 {
+		return
+	}
+	c.dispatch(calls)
+}
+
+// dispatch calls batchFn with the requests of the calls and sends every call its outcome.
+func (c *coalescer) dispatch(calls []*coalescedCall) {
+	reqs := make([]Request, len(calls))
+	for i, call := range calls {
+		reqs[i] = call.req
+	}
+	ctx, cancel := batchContext(calls)
+	defer cancel()
+
+	responses, errs, err := c.callBatchFn(ctx, reqs)
+	for i, call := range calls {
+		r := result{err: err}
+		if err == nil {
+			if i < len(responses) {
+				r.res = responses[i]
+			}
+			if i < len(errs) {
+				r.err = errs[i]
+			}
+			if i >= len(responses) && i >= len(errs) {
+				r.err = fmt.Errorf("service: no result for request %d of a batch of %d", i, len(reqs))
+			}
+		}
+		call.resultCh <- r
+	}
+}
+
+// callBatchFn calls batchFn, converting a panic of batchFn to an error.
+func (c *coalescer) callBatchFn(ctx context.Context, reqs []Request) (responses []Response, errs []error, err error) {
+	defer c.recover(&err)
+
+	responses, errs = c.batchFn(ctx, reqs)
+
+	return responses, errs, nil
+}
+
+// batchContext returns the context of a batch, carrying the values of the context of the first call and the latest
+// deadline of the calls if all of them have one.
+func batchContext(calls []*coalescedCall) (context.Context, context.CancelFunc) {
+	parent := context.WithoutCancel(calls[0].ctx)
+	var latest time.Time
+	for _, call := range calls {
+		deadline, ok := call.ctx.Deadline()
+		if !ok {
+			return context.WithCancel(parent)
+		}
+		if deadline.After(latest) {
+			latest = deadline
+		}
+	}
+
+	return context.WithDeadline(parent, latest)
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchRecorder is a batchFn answering every request with its Data, recording the batches.
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]Request
+}
+
+func (r *batchRecorder) serve(ctx context.Context, reqs []Request) ([]Response, []error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.batches = append(r.batches, reqs)
+	responses := make([]Response, len(reqs))
+	for i, req := range reqs {
+		responses[i] = Response{Data: "response " + req.Data}
+	}
+
+	return responses, make([]error, len(reqs))
+}
+
+func (r *batchRecorder) snapshot() [][]Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([][]Request(nil), r.batches...)
+}
+
+// Test case for three near-simultaneous requests, coalesced in a single call of batchFn. Every caller gets the
+// response of its own request.
+func TestService_Serve_Coalescing(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	recorder := &batchRecorder{}
+	srv := NewServiceWithOptions(noopWork, WithCoalescing(10*time.Millisecond, 10, recorder.serve), WithClock(clock))
+
+	var wg sync.WaitGroup
+	responses := make([]Response, 3)
+	for i, data := range []string{"a", "b", "c"} {
+		wg.Add(1)
+		go func(i int, data string) {
+			defer wg.Done()
+			res, err := srv.Serve(context.Background(), Request{Data: data})
+			if err != nil {
+				t.Errorf("Serve() got err %v, wanted %v", err, nil)
+			}
+			responses[i] = res
+		}(i, data)
+	}
+	waitFor(t, func() bool { return srv.coalescerLen() == 3 })
+	clock.Advance(10 * time.Millisecond)
+	wg.Wait()
+
+	want := []Response{{Data: "response a"}, {Data: "response b"}, {Data: "response c"}}
+	if !reflect.DeepEqual(responses, want) {
+		t.Errorf("Serve() got responses %v, wanted %v", responses, want)
+	}
+	if batches := recorder.snapshot(); len(batches) != 1 || len(batches[0]) != 3 {
+		t.Errorf("batchFn got batches %v, wanted a single batch of %d requests", batches, 3)
+	}
+}
+
+// Test case for a full batch. It is dispatched without waiting for the window.
+func TestService_Serve_CoalescingMaxBatch(t *testing.T) {
+	recorder := &batchRecorder{}
+	srv := NewServiceWithOptions(noopWork, WithCoalescing(time.Minute, 1, recorder.serve))
+
+	res, err := srv.Serve(context.Background(), Request{Data: "a"})
+
+	if err != nil || res.Data != "response a" {
+		t.Errorf("Serve() got (%v, %v), wanted (%v, %v)", res, err, Response{Data: "response a"}, nil)
+	}
+}
+
+// Test case for a caller cancelled before the dispatch. It is removed from the batch.
+func TestService_Serve_CoalescingCancelled(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	recorder := &batchRecorder{}
+	srv := NewServiceWithOptions(noopWork, WithCoalescing(10*time.Millisecond, 10, recorder.serve), WithClock(clock))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		_, err := srv.Serve(ctx, Request{Data: "cancelled"})
+		cancelled <- err
+	}()
+	waitFor(t, func() bool { return srv.coalescerLen() == 1 })
+	served := make(chan error)
+	go func() {
+		_, err := srv.Serve(context.Background(), Request{Data: "served"})
+		served <- err
+	}()
+	waitFor(t, func() bool { return srv.coalescerLen() == 2 })
+
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.Canceled)
+	}
+	// The Service returns as soon as the context is done, maybe before the coalescer removes the call.
+	waitFor(t, func() bool { return srv.coalescerRemoved() == 1 })
+	clock.Advance(10 * time.Millisecond)
+	if err := <-served; err != nil {
+		t.Errorf("Serve() got err %v, wanted %v", err, nil)
+	}
+
+	want := [][]Request{{{Data: "served"}}}
+	if batches := recorder.snapshot(); !reflect.DeepEqual(batches, want) {
+		t.Errorf("batchFn got batches %v, wanted %v", batches, want)
+	}
+}
+
+// Test case for a batchFn returning fewer results than the requests, or panicking. The callers get an error.
+func TestService_Serve_CoalescingBadBatchFn(t *testing.T) {
+	tests := []struct {
+		name    string
+		batchFn func(ctx context.Context, reqs []Request) ([]Response, []error)
+	}{
+		{name: "missing results", batchFn: func(ctx context.Context, reqs []Request) ([]Response, []error) {
+			return nil, nil
+		}},
+		{name: "panic", batchFn: func(ctx context.Context, reqs []Request) ([]Response, []error) {
+			panic("boom")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServiceWithOptions(noopWork, WithCoalescing(time.Minute, 1, tt.batchFn))
+
+			if _, err := srv.Serve(context.Background(), Request{}); err == nil {
+				t.Errorf("Serve() should return an error")
+			}
+		})
+	}
+}
+
+// coalescerLen returns the number of requests of the pending batch.
+func (s *Service) coalescerLen() int {
+	s.coalescer.mu.Lock()
+	defer s.coalescer.mu.Unlock()
+
+	if s.coalescer.pending == nil {
+		return 0
+	}
+
+	return len(s.coalescer.pending.calls)
+}
+
+// coalescerRemoved returns the number of calls removed from the pending batch of the coalescer.
+func (s *Service) coalescerRemoved() int {
+	s.coalescer.mu.Lock()
+	defer s.coalescer.mu.Unlock()
+
+	removed := 0
+	if s.coalescer.pending != nil {
+		for _, call := range s.coalescer.pending.calls {
+			if call.removed {
+				removed++
+			}
+		}
+	}
+
+	return removed
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"reflect"
+)
+
+// WithResponseComparator is an option that sets the function telling if two responses of the same request are
+// equal, used for detecting the non-deterministic work (see WithMismatchHandler). The default compares the responses
+// with reflect.DeepEqual, ignoring the Body, which is a stream that can't be compared without reading it.
+func WithResponseComparator(equal func(a, b Response) bool) Option {
+	return func(s *Service) {
+		s.responseEqual = equal
+	}
+}
+
+// WithMismatchHandler is an option that calls handler when two executions of the same request succeed with
+// different responses (see WithResponseComparator), a sign of non-idempotent or non-deterministic work which is
+// dangerous with WithHedging and makes the comparisons of WithShadow noisy. The executions compared are:
+//   - with WithHedging, the winning copy and every other copy that succeeds, even after it was cancelled;
+//   - with WithShadow, the successful responses of Serve and of the shadow.
+//
+// The handler is called on a background goroutine, after Serve has returned, so it may be called concurrently and
+// must be safe for concurrent use. It must not read the Body of the responses. Close waits for the pending
+// comparisons.
+func WithMismatchHandler(handler func(req Request, a, b Response)) Option {
+	return func(s *Service) {
+		s.onMismatch = handler
+	}
+}
+
+// compareResponses calls the mismatch handler if the responses are not equal.
+func (s *Service) compareResponses(req Request, a, b Response) {
+	if s.onMismatch == nil {
+		return
+	}
+	equal := s.responseEqual
+	if equal == nil {
+		equal = equalIgnoringBody
+	}
+	if !equal(a, b) {
+		s.onMismatch(req, a, b)
+	}
+}
+
+// equalIgnoringBody reports whether the responses are deeply equal, ignoring their Body.
+func equalIgnoringBody(a, b Response) bool {
+	a.Body, b.Body = nil, nil
+
+	return reflect.DeepEqual(a, b)
+}
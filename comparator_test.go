@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test case for two hedged calls returning different data. The mismatch handler fires with both responses once the
+// losing copy returns.
+func TestService_Serve_HedgingMismatch(t *testing.T) {
+	var calls atomic.Int64
+	var mu sync.Mutex
+	var mismatches [][2]Response
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		if calls.Add(1) == 1 {
+			// The original call ignores the cancellation, so it returns after the hedged copy wins.
+			time.Sleep(50 * time.Millisecond)
+			return Response{Data: "original"}, nil
+		}
+		return Response{Data: "hedged"}, nil
+	}, WithHedging(10*time.Millisecond, 1), WithMismatchHandler(func(req Request, a, b Response) {
+		mu.Lock()
+		defer mu.Unlock()
+		mismatches = append(mismatches, [2]Response{a, b})
+	}))
+
+	res, err := srv.Serve(context.Background(), Request{})
+	if err != nil || res.Data != "hedged" {
+		t.Fatalf("Serve() got %v and err %v, wanted %v", res, err, Response{Data: "hedged"})
+	}
+	srv.Close(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(mismatches) != 1 || mismatches[0][0].Data != "hedged" || mismatches[0][1].Data != "original" {
+		t.Errorf("mismatch handler got %v, wanted one mismatch between %q and %q", mismatches, "hedged", "original")
+	}
+}
+
+// Test case for the comparator of the shadow responses. The default comparator reports responses differing only in
+// case, while a case insensitive comparator doesn't.
+func TestService_Serve_ShadowComparator(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []Option
+		wantMismatches int
+	}{
+		{name: "default comparator", wantMismatches: 1},
+		{name: "custom comparator", opts: []Option{WithResponseComparator(func(a, b Response) bool {
+			return strings.EqualFold(a.Data, b.Data)
+		})}, wantMismatches: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mismatches atomic.Int64
+			shadow := ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+				return Response{Data: "RESPONSE"}, nil
+			})
+			opts := append([]Option{WithShadow(shadow, 1), WithMismatchHandler(func(req Request, a, b Response) {
+				mismatches.Add(1)
+			})}, tt.opts...)
+			srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+				return Response{Data: "response"}, nil
+			}, opts...)
+
+			srv.Serve(context.Background(), Request{})
+			srv.Close(context.Background())
+
+			if got := mismatches.Load(); got != int64(tt.wantMismatches) {
+				t.Errorf("got %d mismatches, wanted %d", got, tt.wantMismatches)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+)
+
+// WithResponseCompression is an option that gzips the Body of the successful responses larger than minBytes, and
+// sets Response.Compressed, e.g. for returning it as is with Content-Encoding: gzip.
+// The Body is compressed as it is read, so it is never buffered as a whole. To find out if it is larger than
+// minBytes, up to minBytes+1 bytes are read before Serve returns, unless the Body tells its length with a Len method
+// like *bytes.Reader. Serve waits for them until they are available, or until the Body ends or fails, or until the
+// context is done, in which case it returns the context error and the Body is drained and closed in the background
+// once the read returns. Either way the Body of the response becomes an io.ReadCloser, whose Close closes the
+// original Body if it is an io.Closer, so it must be closed by the caller like any Body.
+// The compression runs after the transform of WithResponseTransform. Values lower or equal to 0 disable the
+// compression.
+func WithResponseCompression(minBytes int) Option {
+	return func(s *Service) {
+		s.compressMin = minBytes
+	}
+}
+
+// compress gzips the Body of the response if it is larger than the threshold of WithResponseCompression. It returns
+// the context error if the context is done before the size of the Body is known.
+func (s *Service) compress(ctx context.Context, res Response) (Response, error) {
+	if s.compressMin <= 0 || res.Body == nil || res.Compressed {
+		return res, nil
+	}
+
+	// Tell the size of the body without reading it, if it knows it.
+	if l, ok := res.Body.(interface{ Len() int }); ok {
+		if l.Len() <= s.compressMin {
+			res.Body = readCloser{Reader: res.Body, body: res.Body}
+			return res, nil
+		}
+		res.Body = &gzipReader{src: res.Body, body: res.Body}
+		res.Compressed = true
+		return res, nil
+	}
+
+	// Read just enough of the body to tell if it is larger than the threshold, on its own goroutine so that a slow
+	// body doesn't keep Serve waiting past the context.
+	peeked := make(chan peek, 1)
+	s.inflight.Add(1)
+	go func() {
+		defer s.inflight.Done()
+		head := make([]byte, s.compressMin+1)
+		n, err := io.ReadFull(res.Body, head)
+		peeked <- peek{head: head[:n], err: err}
+	}()
+	var p peek
+	select {
+	case p = <-peeked:
+	case <-ctx.Done():
+		// Nobody is going to read the body anymore, so release it once the read returns.
+		s.inflight.Add(1)
+		go func() {
+			defer s.inflight.Done()
+			<-peeked
+			DrainAndClose(res.Body)
+		}()
+		return Response{}, contextErr(ctx)
+	}
+
+	// Put the head back in front of the rest of the body.
+	rest := io.MultiReader(bytes.NewReader(p.head), res.Body)
+	if p.err != nil && p.err != io.EOF && p.err != io.ErrUnexpectedEOF {
+		// Let the caller see the error while reading the body.
+		rest = io.MultiReader(bytes.NewReader(p.head), errReader{err: p.err})
+	}
+	if len(p.head) <= s.compressMin || p.err != nil {
+		res.Body = readCloser{Reader: rest, body: res.Body}
+		return res, nil
+	}
+
+	res.Body = &gzipReader{src: rest, body: res.Body}
+	res.Compressed = true
+
+	return res, nil
+}
+
+// peek is the head of a body, read to tell its size, along with the error of the read.
+type peek struct {
+	head []byte
+	err  error
+}
+
+// readCloser is a reader whose Close closes the original body, if it is an io.Closer.
+type readCloser struct {
+	io.Reader
+	body io.Reader
+}
+
+// Close closes the original body, if it is an io.Closer.
+func (r readCloser) Close() error {
+	if c, ok := r.body.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
+
+// errReader is a reader that always fails with err.
+type errReader struct {
+	err error
+}
+
+// Read returns the error.
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// gzipReader is a reader of the gzipped src, compressing it as it is read.
+type gzipReader struct {
+	src io.Reader
+	// body is the original body, closed by Close.
+	body io.Reader
+
+	// buf holds the compressed bytes not read yet.
+	buf bytes.Buffer
+	zw  *gzip.Writer
+	// chunk holds the bytes of src being compressed.
+	chunk []byte
+	// err is the error to return once buf is empty, io.EOF after the whole src has been compressed.
+	err error
+}
+
+// Read reads the next compressed bytes, compressing the next chunk of src when there are none left.
+func (r *gzipReader) Read(p []byte) (int, error) {
+	if r.zw == nil {
+		r.zw = gzip.NewWriter(&r.buf)
+		r.chunk = make([]byte, 32<<10)
+	}
+	for r.buf.Len() == 0 && r.err == nil {
+		n, err := r.src.Read(r.chunk)
+		if n > 0 {
+			// Writing to a bytes.Buffer never fails.
+			r.zw.Write(r.chunk[:n])
+			r.zw.Flush()
+		}
+		switch {
+		case err == io.EOF:
+			r.zw.Close()
+			r.err = io.EOF
+		case err != nil:
+			r.err = err
+		}
+	}
+	if r.buf.Len() > 0 {
+		return r.buf.Read(p)
+	}
+
+	return 0, r.err
+}
+
+// Close closes the original body, if it is an io.Closer.
+func (r *gzipReader) Close() error {
+	return readCloser{body: r.body}.Close()
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// closeRecorder is a body recording if it was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+// Close records the close.
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+// Test case for the response compression. Bodies larger than the threshold are gzipped, smaller ones are returned
+// as they are, and either way closing the body closes the original one.
+func TestService_Serve_ResponseCompression(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		wantCompressed bool
+	}{
+		{name: "below threshold", body: "small", wantCompressed: false},
+		{name: "at threshold", body: strings.Repeat("a", 10), wantCompressed: false},
+		{name: "above threshold", body: strings.Repeat("large body ", 1000), wantCompressed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &closeRecorder{Reader: strings.NewReader(tt.body)}
+			srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+				return Response{Body: body}, nil
+			}, WithResponseCompression(10))
+
+			res, err := srv.Serve(context.Background(), Request{})
+			if err != nil {
+				t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+			}
+			if res.Compressed != tt.wantCompressed {
+				t.Errorf("Serve() got compressed %v, wanted %v", res.Compressed, tt.wantCompressed)
+			}
+			r := res.Body
+			if res.Compressed {
+				if r, err = gzip.NewReader(res.Body); err != nil {
+					t.Fatalf("gzip.NewReader() got err %v, wanted %v", err, nil)
+				}
+			}
+			got, err := io.ReadAll(r)
+			if err != nil || string(got) != tt.body {
+				t.Errorf("got body %q and err %v, wanted %q", got, err, tt.body)
+			}
+			res.Body.(io.Closer).Close()
+			if !body.closed {
+				t.Errorf("original body not closed, wanted closed")
+			}
+		})
+	}
+}
+
+// Test case for a body failing while it is compressed. The error is returned by the reader of the compressed body.
+func TestService_Serve_ResponseCompressionError(t *testing.T) {
+	bodyErr := errors.New("broken body")
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Body: io.MultiReader(bytes.NewReader(make([]byte, 100)), errReader{err: bodyErr})}, nil
+	}, WithResponseCompression(10))
+
+	res, err := srv.Serve(context.Background(), Request{})
+	if err != nil {
+		t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+	}
+	if _, err := io.ReadAll(res.Body); !errors.Is(err, bodyErr) {
+		t.Errorf("ReadAll() got err %v, wanted %v", err, bodyErr)
+	}
+}
+
+// slowBody is a body whose first read blocks until it is released, recording if it was closed.
+type slowBody struct {
+	release chan struct{}
+	closed  atomic.Bool
+}
+
+// Read blocks until the body is released, then ends the body.
+func (b *slowBody) Read(p []byte) (int, error) {
+	<-b.release
+	return 0, io.EOF
+}
+
+// Close records the close.
+func (b *slowBody) Close() error {
+	b.closed.Store(true)
+	return nil
+}
+
+// Test case for a body too slow to tell its size before the deadline. Serve returns the context error at the
+// deadline, and the body is closed once its read returns.
+func TestService_Serve_ResponseCompressionSlowBody(t *testing.T) {
+	body := &slowBody{release: make(chan struct{})}
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Body: body}, nil
+	}, WithResponseCompression(10))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Serve() returned after %v, wanted at the deadline", elapsed)
+	}
+	close(body.release)
+	if err := srv.Close(context.Background()); err != nil {
+		t.Fatalf("Close() got err %v, wanted %v", err, nil)
+	}
+	if !body.closed.Load() {
+		t.Errorf("body not closed, wanted closed")
+	}
+}
+
+// Test case for a body telling its length. It is compressed without reading it before Serve returns.
+func TestService_Serve_ResponseCompressionLen(t *testing.T) {
+	want := strings.Repeat("large body ", 1000)
+	body := strings.NewReader(want)
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Body: body}, nil
+	}, WithResponseCompression(10))
+
+	res, err := srv.Serve(context.Background(), Request{})
+	if err != nil || !res.Compressed {
+		t.Fatalf("Serve() got compressed %v and err %v, wanted %v and %v", res.Compressed, err, true, nil)
+	}
+	if body.Len() != len(want) {
+		t.Errorf("got %d bytes left in the body, wanted the body unread", body.Len())
+	}
+	zr, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() got err %v, wanted %v", err, nil)
+	}
+	if got, err := io.ReadAll(zr); err != nil || string(got) != want {
+		t.Errorf("got body of %d bytes and err %v, wanted %d bytes", len(got), err, len(want))
+	}
+}
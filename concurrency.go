@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithMaxConcurrency is an option that limits the number of work executions running at the same time to n,
+// because every call of Serve launches a goroutine for the work, and under load the goroutines would be unbounded.
+// Additional callers block until a slot frees up, or until their context gets cancelled, in which case the context
+// error is returned. A slot is held until the work returns, even if Serve has already returned because the context
+// got cancelled, so abandoned work still counts. A n lower or equal to 0 disables the limit, and it is rejected by
+// New.
+func WithMaxConcurrency(n int) Option {
+	return func(s *Service) {
+		if n <= 0 {
+			s.invalidOption("WithMaxConcurrency(%d): limit lower than 1", n)
+			s.sem = nil
+			return
+		}
+		s.sem = make(chan struct{}, n)
+	}
+}
+
+// InFlight returns the number of work executions currently holding a slot of WithMaxConcurrency.
+// It is always 0 without WithMaxConcurrency.
+func (s *Service) InFlight() int {
+	if s.fairSem != nil {
+		return s.fairSem.Len()
+	}
+
+	return len(s.sem)
+}
+
+// QueueDepths returns the number of callers waiting for a slot of WithMaxConcurrency by Request.Priority. It is
+// always empty without WithFairness.
+func (s *Service) QueueDepths() map[int]int {
+	if s.fairSem == nil {
+		return map[int]int{}
+	}
+
+	return s.fairSem.Depths()
+}
+
+// acquire takes a slot of the concurrency limit, if there is one, blocking until a slot frees up or the context
+// gets cancelled.
+func (s *Service) acquire(ctx context.Context, priority int) error {
+	if s.fairSem != nil {
+		if err := s.fairSem.AcquirePriority(ctx, priority); err != nil {
+			return fmt.Errorf("service: waiting for a concurrency slot: %w", err)
+		}
+		return nil
+	}
+	if s.sem == nil {
+		return nil
+	}
+
+	select {
+	case s.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("service: waiting for a concurrency slot: %w", contextErr(ctx))
+	}
+}
+
+// release frees the slot taken by acquire.
+func (s *Service) release() {
+	if s.fairSem != nil {
+		s.fairSem.Release()
+		return
+	}
+	if s.sem == nil {
+		return
+	}
+
+	<-s.sem
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Test case for the concurrency limit. The 11th concurrent call blocks until one of the first 10 finishes.
+func TestService_Serve_MaxConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		<-release
+		return Response{Data: "success"}, nil
+	}, WithMaxConcurrency(10))
+
+	done := make(chan error, 11)
+	for i := 0; i < 10; i++ {
+		go func() {
+			_, err := srv.Serve(context.Background(), Request{})
+			done <- err
+		}()
+	}
+	waitFor(t, func() bool { return srv.InFlight() == 10 })
+
+	eleventh := make(chan error, 1)
+	go func() {
+		_, err := srv.Serve(context.Background(), Request{})
+		eleventh <- err
+	}()
+
+	select {
+	case <-eleventh:
+		t.Fatalf("Serve() returned, wanted the 11th call to block")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if srv.Attempts() != 10 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 10)
+	}
+
+	close(release)
+	if err := <-eleventh; err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := <-done; err != nil {
+			t.Errorf("Serve() should not return an error, got %v", err)
+		}
+	}
+	waitFor(t, func() bool { return srv.InFlight() == 0 })
+}
+
+// Test case for a cancellation while waiting for a slot. Serve returns the context error without calling the work.
+func TestService_Serve_MaxConcurrencyCancelled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		<-release
+		return Response{Data: "success"}, nil
+	}, WithMaxConcurrency(1))
+
+	go srv.Serve(context.Background(), Request{})
+	waitFor(t, func() bool { return srv.InFlight() == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if srv.Attempts() != 1 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 1)
+	}
+}
+
+// waitFor polls the condition until it is true, failing the test if it does not happen within a second.
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Test case for the queue wait of a call blocked by the concurrency limit, recorded in the log event and the metrics
+func TestService_Serve_QueueWait(t *testing.T) {
+	release := make(chan struct{})
+	clock := NewFakeClock(time.Now())
+	registry := prometheus.NewRegistry()
+	events := make(chan LogEvent, 2)
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		<-release
+		return Response{Data: req.Data}, nil
+	},
+		WithMaxConcurrency(1),
+		WithFairness(true),
+		WithClock(clock),
+		WithMetrics(registry),
+		WithLogger(func(ctx context.Context, event LogEvent) {
+			events <- event
+		}),
+	)
+	defer srv.UnregisterMetrics()
+
+	first := srv.ServeAsync(context.Background(), Request{Data: "first"})
+	waitFor(t, func() bool { return srv.InFlight() == 1 })
+	second := srv.ServeAsync(context.Background(), Request{Data: "second"})
+	waitFor(t, func() bool {
+		srv.fairSem.mu.Lock()
+		defer srv.fairSem.mu.Unlock()
+		return srv.fairSem.waiters.Len() == 1
+	})
+
+	clock.Advance(time.Second)
+	close(release)
+	first.Wait()
+	second.Wait()
+
+	waits := map[string]time.Duration{}
+	for i := 0; i < 2; i++ {
+		e := <-events
+		waits[e.Request.Data] = e.QueueWait
+	}
+	wanted := map[string]time.Duration{"first": 0, "second": time.Second}
+	if !reflect.DeepEqual(waits, wanted) {
+		t.Errorf("got queue waits %v, wanted %v", waits, wanted)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() returned error %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() != "service_queue_wait_seconds" {
+			continue
+		}
+		h := f.GetMetric()[0].GetHistogram()
+		if h.GetSampleCount() != 2 || h.GetSampleSum() != 1 {
+			t.Errorf("service_queue_wait_seconds got count %d and sum %v, wanted %d and %v",
+				h.GetSampleCount(), h.GetSampleSum(), 2, 1)
+		}
+		return
+	}
+	t.Errorf("service_queue_wait_seconds was not gathered")
+}
@@ -0,0 +1,48 @@
+package service
+
+import "context"
+
+// WithContextValues is an option that makes the values visible to the work through its context, e.g. feature flags
+// or a tenant ID, without the callers having to add them to the context they pass to Serve.
+// When the context of the caller already has a value for the same key, the work sees the value of the caller,
+// unless WithOverride(true) is used, in which case the work sees the injected value. Values of the caller for other
+// keys are always visible. The map is copied, so changing it afterwards has no effect.
+func WithContextValues(values map[any]any) Option {
+	return func(s *Service) {
+		s.ctxValues = make(map[any]any, len(values))
+		for k, v := range values {
+			s.ctxValues[k] = v
+		}
+	}
+}
+
+// WithOverride is an option that makes the values of WithContextValues take precedence over the values of the
+// context of the caller with the same key.
+func WithOverride(override bool) Option {
+	return func(s *Service) {
+		s.ctxOverride = override
+	}
+}
+
+// valuesContext is a context that adds a set of values to its parent, in a single layer instead of a chain of
+// context.WithValue calls.
+type valuesContext struct {
+	context.Context
+	values map[any]any
+	// override makes the values take precedence over the values of the parent.
+	override bool
+}
+
+// Value returns the value of the key, looking it up according to the precedence of the values.
+func (c valuesContext) Value(key any) any {
+	if !c.override {
+		if v := c.Context.Value(key); v != nil {
+			return v
+		}
+	}
+	if v, ok := c.values[key]; ok {
+		return v
+	}
+
+	return c.Context.Value(key)
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type tenantKey struct{}
+type flagKey struct{}
+type callerKey struct{}
+
+// Test case for the injected values. The values of the caller with the same key take precedence.
+func TestService_Serve_ContextValues(t *testing.T) {
+	ts := &TestService{RecordKeys: []any{tenantKey{}, flagKey{}, callerKey{}}}
+	values := map[any]any{tenantKey{}: "tenant-1", flagKey{}: true}
+	srv := NewServiceWithOptions(ts.Serve, WithContextValues(values))
+	values[tenantKey{}] = "changed"
+
+	ctx := context.WithValue(context.Background(), flagKey{}, false)
+	ctx = context.WithValue(ctx, callerKey{}, "caller")
+	srv.Serve(ctx, Request{})
+
+	want := map[any]any{tenantKey{}: "tenant-1", flagKey{}: false, callerKey{}: "caller"}
+	if !reflect.DeepEqual(ts.Recorder.CtxValues, want) {
+		t.Errorf("got context values %v, wanted %v", ts.Recorder.CtxValues, want)
+	}
+}
+
+// Test case for the injected values overriding the values of the caller with the same key
+func TestService_Serve_ContextValuesOverride(t *testing.T) {
+	ts := &TestService{RecordKeys: []any{flagKey{}, callerKey{}}}
+	srv := NewServiceWithOptions(ts.Serve, WithContextValues(map[any]any{flagKey{}: true}), WithOverride(true))
+
+	ctx := context.WithValue(context.Background(), flagKey{}, false)
+	ctx = context.WithValue(ctx, callerKey{}, "caller")
+	srv.Serve(ctx, Request{})
+
+	want := map[any]any{flagKey{}: true, callerKey{}: "caller"}
+	if !reflect.DeepEqual(ts.Recorder.CtxValues, want) {
+		t.Errorf("got context values %v, wanted %v", ts.Recorder.CtxValues, want)
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNoDeadline is the error returned by Serve when WithRequireDeadline is used and the context has no deadline.
+var ErrNoDeadline = errors.New("service: context without deadline")
+
+// WithRequireDeadline is an option that makes Serve return ErrNoDeadline immediately when the context has no
+// deadline, e.g. context.Background(), forcing the callers to bound every request so that a hanging work can't
+// block them forever. Use WithDefaultTimeout for a softer approach.
+// It can't be used along with WithDefaultTimeout, in which case NewServiceWithOptions panics and New fails.
+func WithRequireDeadline() Option {
+	return func(s *Service) {
+		s.requireDeadline = true
+	}
+}
+
+// WithDefaultTimeout is an option that bounds to d the calls of Serve whose context has no deadline. Contexts with
+// a deadline are not affected, even if the deadline is later than d. Use WithTimeout for bounding every call.
+// It can't be used along with WithRequireDeadline, in which case NewServiceWithOptions panics and New fails.
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(s *Service) {
+		if d < 0 {
+			s.invalidOption("WithDefaultTimeout(%v): negative timeout", d)
+		}
+		s.defaultTimeout = d
+	}
+}
+
+// checkDeadline returns ErrNoDeadline if a deadline is required and the context has none.
+func (s *Service) checkDeadline(ctx context.Context) error {
+	if !s.requireDeadline {
+		return nil
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		return ErrNoDeadline
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultDeadlineHeader is the suggested header for propagating the remaining time of a request over HTTP.
+const DefaultDeadlineHeader = "X-Request-Deadline"
+
+// WithDeadlineHeader writes the remaining time of the context, in milliseconds, in the header of the outgoing HTTP
+// request, so that the server can stop working on it when the client stops waiting (see DeadlineFromHeader).
+// Sending the remaining time instead of the deadline itself makes the propagation immune to clock skew between the
+// two hosts. The header is not set if the context has no deadline, and it is set to 0 if the deadline has passed.
+// The work should call it on every outbound request, e.g. WithDeadlineHeader(ctx, httpReq, DefaultDeadlineHeader).
+func WithDeadlineHeader(ctx context.Context, req *http.Request, header string) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return
+	}
+
+	remaining := time.Until(deadline).Milliseconds()
+	if remaining < 0 {
+		remaining = 0
+	}
+	req.Header.Set(header, strconv.FormatInt(remaining, 10))
+}
+
+// DeadlineFromHeader returns a context derived from the context of the incoming HTTP request, bounded by the
+// remaining time found in the header (see WithDeadlineHeader). The cancel function must always be called.
+// If the header is missing or is not an integer, the context has no deadline other than the one of the request
+// context. If the header is zero or negative, the client has already given up, so the returned context has
+// already exceeded its deadline. A header too large for a time.Duration is clamped to the largest one.
+func DeadlineFromHeader(r *http.Request, header string) (context.Context, context.CancelFunc) {
+	ms, err := strconv.ParseInt(r.Header.Get(header), 10, 64)
+	if err != nil {
+		return context.WithCancel(r.Context())
+	}
+	if ms < 0 {
+		ms = 0
+	}
+	// Clamp the huge values, which would overflow the duration and expire the context right away.
+	if ms > math.MaxInt64/int64(time.Millisecond) {
+		ms = math.MaxInt64 / int64(time.Millisecond)
+	}
+
+	return context.WithTimeout(r.Context(), time.Duration(ms)*time.Millisecond)
+}
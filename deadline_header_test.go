@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Test case for writing the remaining time of the context in the header
+func TestWithDeadlineHeader(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	bounded, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		header string
+	}{
+		{name: "no deadline", ctx: context.Background(), header: ""},
+		{name: "expired deadline", ctx: expired, header: "0"},
+		{name: "future deadline", ctx: bounded, header: "60000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			WithDeadlineHeader(tt.ctx, req, DefaultDeadlineHeader)
+
+			got := req.Header.Get(DefaultDeadlineHeader)
+			if tt.header == "60000" {
+				// Allow for the time elapsed since the creation of the context.
+				if ms, err := strconv.Atoi(got); err != nil || ms < 59000 || ms > 60000 {
+					t.Errorf("got header %q, wanted about %q", got, tt.header)
+				}
+				return
+			}
+			if got != tt.header {
+				t.Errorf("got header %q, wanted %q", got, tt.header)
+			}
+		})
+	}
+}
+
+// Test case for reconstructing the context from the header
+func TestDeadlineFromHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		deadline bool
+		expired  bool
+	}{
+		{name: "missing header", header: "", deadline: false},
+		{name: "invalid header", header: "soon", deadline: false},
+		{name: "negative header", header: "-10", deadline: true, expired: true},
+		{name: "zero header", header: "0", deadline: true, expired: true},
+		{name: "positive header", header: "60000", deadline: true},
+		{name: "huge header", header: "9223372036854775807", deadline: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set(DefaultDeadlineHeader, tt.header)
+			}
+
+			ctx, cancel := DeadlineFromHeader(r, DefaultDeadlineHeader)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if ok != tt.deadline {
+				t.Fatalf("got deadline %v, wanted deadline %v", ok, tt.deadline)
+			}
+			if tt.expired != errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				t.Errorf("got context err %v, wanted expired %v", ctx.Err(), tt.expired)
+			}
+			if tt.header == "60000" && time.Until(deadline) < 59*time.Second {
+				t.Errorf("got deadline in %v, wanted in about %v", time.Until(deadline), time.Minute)
+			}
+			if tt.name == "huge header" && time.Until(deadline) < 100*365*24*time.Hour {
+				t.Errorf("got deadline in %v, wanted in about %v", time.Until(deadline), time.Duration(math.MaxInt64))
+			}
+		})
+	}
+}
+
+// Test case for the deadline propagated end to end, from the client to the server
+func TestDeadlineHeader_EndToEnd(t *testing.T) {
+	remaining := make(chan time.Duration, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := DeadlineFromHeader(r, DefaultDeadlineHeader)
+		defer cancel()
+		deadline, _ := ctx.Deadline()
+		remaining <- time.Until(deadline)
+	}))
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	WithDeadlineHeader(ctx, req, DefaultDeadlineHeader)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do() got err %v, wanted %v", err, nil)
+	}
+	resp.Body.Close()
+
+	if d := <-remaining; d <= 0 || d > 5*time.Second {
+		t.Errorf("got remaining time %v on the server, wanted less than %v", d, 5*time.Second)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test case for a required deadline. The contexts without deadline are rejected without calling the work.
+func TestService_Serve_RequireDeadline(t *testing.T) {
+	tests := []struct {
+		name     string
+		deadline bool
+		wantErr  error
+		attempts int
+	}{
+		{name: "without deadline", deadline: false, wantErr: ErrNoDeadline, attempts: 0},
+		{name: "with deadline", deadline: true, wantErr: nil, attempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServiceWithOptions((&TestService{}).Serve, WithRequireDeadline())
+			ctx := context.Background()
+			if tt.deadline {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, time.Minute)
+				defer cancel()
+			}
+
+			_, err := srv.Serve(ctx, Request{})
+
+			if err != tt.wantErr {
+				t.Errorf("Serve() got err %v, wanted %v", err, tt.wantErr)
+			}
+			if srv.Attempts() != tt.attempts {
+				t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), tt.attempts)
+			}
+		})
+	}
+}
+
+// Test case for the default timeout applied to a context without deadline
+func TestService_Serve_DefaultTimeout(t *testing.T) {
+	ts := &TestService{DelayReponse: time.Second}
+	srv := NewServiceWithOptions(ts.Serve, WithDefaultTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Serve() returned after %v, wanted about %v", elapsed, 20*time.Millisecond)
+	}
+}
+
+// Test case for the default timeout being ignored when the context has a deadline, even a later one
+func TestService_Serve_DefaultTimeoutCallerDeadline(t *testing.T) {
+	ts := &TestService{DelayReponse: 50 * time.Millisecond}
+	srv := NewServiceWithOptions(ts.Serve, WithDefaultTimeout(10*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	_, err := srv.Serve(ctx, Request{})
+
+	if err != nil {
+		t.Errorf("Serve() got err %v, wanted %v", err, nil)
+	}
+}
+
+// Test case for the mutually exclusive deadline options. The construction panics.
+func TestNewServiceWithOptions_RequireDeadlineAndDefaultTimeout(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("NewServiceWithOptions() should panic")
+		}
+	}()
+
+	NewServiceWithOptions((&TestService{}).Serve, WithRequireDeadline(), WithDefaultTimeout(time.Second))
+}
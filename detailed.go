@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+)
+
+// ServeResult is the outcome of ServeDetailed. Its flags mirror the ones of the TestService.Recorder, so that the
+// production and the test services can be asserted in the same way.
+type ServeResult struct {
+	// Response is the response of Serve
+	Response Response
+	// Err is the error of Serve
+	Err error
+	// CtxCancelled is a flag showing if the request failed because the context was cancelled
+	CtxCancelled bool
+	// CtxDeadlineExceeded is a flag showing if the request failed because the context exceeded a deadline
+	CtxDeadlineExceeded bool
+	// Partial is a flag showing if the Response is a partial result, returned because the context was done before
+	// the work returned (see WithPartialResults)
+	Partial bool
+	// Cause is the original error of the request when it was replaced by the fallback (see WithFallback), even if
+	// the fallback succeeded and Err is nil. It is nil otherwise.
+	Cause error
+}
+
+// ServeDetailed serves the request like Serve, and reports whether a failure was caused by the cancellation or by
+// the deadline of the context, without having to inspect the error.
+func (s *Service) ServeDetailed(ctx context.Context, req Request) ServeResult {
+	var d details
+	res, err := s.run(ctx, req, &d)
+
+	return ServeResult{
+		Response:            res,
+		Err:                 err,
+		CtxCancelled:        errors.Is(err, context.Canceled),
+		CtxDeadlineExceeded: errors.Is(err, context.DeadlineExceeded),
+		Partial:             d.partial,
+		Cause:               d.cause,
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Test case for the flags of the detailed result, for every outcome of Serve
+func TestService_ServeDetailed(t *testing.T) {
+	workErr := errors.New("error")
+	srv := NewServiceCtx(func(ctx context.Context, req Request) (Response, error) {
+		switch req.Data {
+		case "error":
+			return Response{}, workErr
+		case "slow":
+			<-ctx.Done()
+			return Response{}, ctx.Err()
+		}
+		return Response{Data: "success"}, nil
+	})
+	deadlineCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		req    Request
+		result ServeResult
+	}{
+		{
+			name:   "success",
+			ctx:    context.Background(),
+			req:    Request{Data: "success"},
+			result: ServeResult{Response: Response{Data: "success"}},
+		},
+		{
+			name:   "error",
+			ctx:    context.Background(),
+			req:    Request{Data: "error"},
+			result: ServeResult{Err: workErr},
+		},
+		{
+			name:   "deadline exceeded",
+			ctx:    deadlineCtx,
+			req:    Request{Data: "slow"},
+			result: ServeResult{Err: context.DeadlineExceeded, CtxDeadlineExceeded: true},
+		},
+		{
+			name:   "cancelled",
+			ctx:    cancelledCtx,
+			req:    Request{Data: "slow"},
+			result: ServeResult{Err: context.Canceled, CtxCancelled: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := srv.ServeDetailed(tt.ctx, tt.req)
+			if !reflect.DeepEqual(result, tt.result) {
+				t.Errorf("ServeDetailed() got %+v, wanted %+v", result, tt.result)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Test case for the error-unwrapping contract. Whatever stage the request is stopped at, the error returned by Serve
+// can be matched with errors.Is against the context error.
+func TestService_Serve_ContextErrorsThroughOptions(t *testing.T) {
+	slowWork := func(ctx context.Context, req Request) (Response, error) {
+		<-ctx.Done()
+		return Response{}, ctx.Err()
+	}
+	failingWork := func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, errors.New("error")
+	}
+
+	stages := []struct {
+		name string
+		// newService creates the Service and makes sure the next call of Serve will be stopped at the stage.
+		newService func() *Service
+		// noDeadline is true for the stages that a deadline can't stop at.
+		noDeadline bool
+	}{
+		{
+			name: "full stack while working",
+			newService: func() *Service {
+				tracer, _ := newTestTracer()
+				return NewServiceWithOptions(slowWork,
+					WithRetry(3, time.Millisecond),
+					WithExponentialBackoff(time.Millisecond, time.Millisecond, true),
+					WithRetryIf(func(error) bool { return true }),
+					WithCircuitBreaker(10, time.Minute),
+					WithRateLimit(1000, 10),
+					WithMaxConcurrency(10),
+					WithHedging(time.Millisecond, 2),
+					WithCache(time.Minute, nil),
+					WithSingleFlight(nil),
+					WithMetrics(prometheus.NewRegistry()),
+					WithTracer(tracer),
+					WithLogger(func(context.Context, LogEvent) {}),
+					WithPanicHandler(func(any, []byte) error { return ErrPanic }),
+					WithMinBudget(time.Millisecond),
+					WithFallback(func(ctx context.Context, req Request, cause error) (Response, error) {
+						return Response{}, cause
+					}),
+				)
+			},
+		},
+		{
+			name: "waiting for the retry backoff",
+			newService: func() *Service {
+				return NewServiceWithOptions(failingWork, WithRetry(3, time.Minute))
+			},
+			// A backoff beyond the deadline is skipped, so only a cancellation can interrupt it.
+			noDeadline: true,
+		},
+		{
+			name: "waiting for the rate limiter",
+			newService: func() *Service {
+				srv := NewServiceWithOptions(failingWork, WithRateLimit(1, 1))
+				srv.Serve(context.Background(), Request{})
+				return srv
+			},
+		},
+		{
+			name: "waiting for a concurrency slot",
+			newService: func() *Service {
+				srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+					time.Sleep(200 * time.Millisecond)
+					return Response{}, nil
+				}, WithMaxConcurrency(1))
+				go srv.Serve(context.Background(), Request{})
+				for srv.InFlight() == 0 {
+					time.Sleep(time.Millisecond)
+				}
+				return srv
+			},
+		},
+	}
+
+	for _, stage := range stages {
+		t.Run(stage.name+" timeout", func(t *testing.T) {
+			if stage.noDeadline {
+				t.Skip("a deadline can't stop the request at this stage")
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			_, err := stage.newService().Serve(ctx, Request{})
+
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+			}
+		})
+		t.Run(stage.name+" cancellation", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+
+			_, err := stage.newService().Serve(ctx, Request{})
+
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Serve() got err %v, wanted %v", err, context.Canceled)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/psampaz/service"
+)
+
+func main() {
+	// Create a service that serves a lower-fidelity response when less than 50ms are left
+	srv := service.NewServiceWithOptions(func(ctx context.Context, req service.Request) (service.Response, error) {
+		if req.Budget != 0 && req.Budget < 50*time.Millisecond {
+			return service.Response{Data: "approximate response to " + req.Data}, nil
+		}
+		// Plenty of time, or no deadline at all: do the expensive work
+		time.Sleep(10 * time.Millisecond)
+		return service.Response{Data: "exact response to " + req.Data}, nil
+	}, service.WithBudgetInjection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	res, err := srv.Serve(ctx, service.Request{Data: "request data"})
+	fmt.Printf("%+v %v\n", res.Data, err)
+	// exact response to request data <nil>
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	res, err = srv.Serve(ctx, service.Request{Data: "request data"})
+	fmt.Printf("%+v %v\n", res.Data, err)
+	// approximate response to request data <nil>
+
+	res, err = srv.Serve(context.Background(), service.Request{Data: "request data"})
+	fmt.Printf("%+v %v\n", res.Data, err)
+	// exact response to request data <nil>
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/psampaz/service"
+)
+
+func main() {
+	// A naive cache holding the last successful response, to be served as a stale response on failure
+	var mu sync.Mutex
+	var last service.Response
+
+	// Define a function to simulate work that gets slower on every call
+	calls := 0
+	work := func(ctx context.Context, req service.Request) (service.Response, error) {
+		calls++
+		select {
+		case <-time.After(time.Duration(calls) * 400 * time.Millisecond):
+		case <-ctx.Done():
+			return service.Response{}, ctx.Err()
+		}
+
+		res := service.Response{Data: fmt.Sprintf("srv response %d", calls)}
+		mu.Lock()
+		last = res
+		mu.Unlock()
+
+		return res, nil
+	}
+
+	// Serve the last successful response when the work fails or times out
+	fallback := func(ctx context.Context, req service.Request, cause error) (service.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if last == (service.Response{}) {
+			return service.Response{}, cause
+		}
+
+		return last, nil
+	}
+
+	srv := service.NewServiceWithOptions(work, service.WithFallback(fallback))
+
+	for i := 0; i < 2; i++ {
+		// Create a context with timeout of 600 milliseconds.
+		ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+
+		// Serve the request
+		response, err := srv.Serve(ctx, service.Request{Data: "request"})
+		cancel()
+
+		fmt.Printf("Respone %+v\n", response)
+		fmt.Printf("Error %+v\n", err)
+	}
+	// Respone {Data:srv response 1}
+	// Error <nil>
+	// Respone {Data:srv response 1}
+	// Error <nil>
+}
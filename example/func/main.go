@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/psampaz/service"
+)
+
+func main() {
+	// Create a stateful test service that fails the first time and succeeds after
+	calls := 0
+	fs := service.FuncService{
+		Fn: func(ctx context.Context, req service.Request) (service.Response, error) {
+			calls++
+			if calls == 1 {
+				return service.Response{}, errors.New("temporary error")
+			}
+			return service.Response{Data: "response to " + req.Data}, nil
+		},
+	}
+
+	// Use it as the work of a Service that retries the failed calls
+	srv := service.NewServiceWithOptions(fs.Serve, service.WithRetry(2, 0))
+	res, err := srv.Serve(context.Background(), service.Request{Data: "request data"})
+
+	fmt.Printf("%+v %v\n", res, err)
+	// {Data:response to request data} <nil>
+
+	fmt.Printf("%+v\n", fs.Recorder.Calls)
+	// [
+	//  {Request:{Data:request data} Response:{Data:} Err:temporary error}
+	//  {Request:{Data:request data} Response:{Data:response to request data} Err:<nil>}
+	// ]
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/psampaz/service"
+)
+
+// GreeterClient is the client of a gRPC service, like the ones generated by protoc-gen-go-grpc
+type GreeterClient interface {
+	SayHello(ctx context.Context, name string) (string, error)
+}
+
+// mockGreeterClient is a mock of the GreeterClient, reporting the deadline that gRPC would send to the server
+type mockGreeterClient struct{}
+
+func (mockGreeterClient) SayHello(ctx context.Context, name string) (string, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return "hello " + name + " without deadline", nil
+	}
+	// gRPC sends the remaining time in the grpc-timeout metadata, rounded to the millisecond or coarser
+	return fmt.Sprintf("hello %s within %v", name, time.Until(deadline).Round(100*time.Millisecond)), nil
+}
+
+func main() {
+	var client GreeterClient = mockGreeterClient{}
+
+	// Create a service making a gRPC call with the context of the work, bounded to 1 second. gRPC propagates the
+	// deadline of the context by itself, and WithWorkContext cancels the call on the server once Serve returns.
+	srv := service.NewServiceWithOptions(func(ctx context.Context, req service.Request) (service.Response, error) {
+		msg, err := client.SayHello(ctx, req.Data)
+		return service.Response{Data: msg}, err
+	}, service.WithWorkContext(), service.WithTimeout(time.Second))
+
+	// The deadline of the caller is later than the timeout of the Service, so the timeout applies
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	res, err := srv.Serve(ctx, service.Request{Data: "gopher"})
+	fmt.Println(res.Data, err)
+	// hello gopher within 1s <nil>
+
+	// The deadline of the caller is earlier than the timeout of the Service, so it applies
+	ctx, cancel = context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	res, err = srv.Serve(ctx, service.Request{Data: "gopher"})
+	fmt.Println(res.Data, err)
+	// hello gopher within 500ms <nil>
+}
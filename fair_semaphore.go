@@ -0,0 +1,144 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// WithFairness is an option that makes the callers waiting for a slot of WithMaxConcurrency get it in the order they
+// arrived, so that no caller starves under sustained load. Without fairness, any of the waiting callers may get a
+// freed slot. Fairness has a small cost, so it is disabled by default. It has no effect without WithMaxConcurrency.
+// With fairness the callers get the slots by Request.Priority first: a caller gets a freed slot before every waiting
+// caller with a lower priority, even if they arrived earlier, while the callers with the same priority get the slots
+// in the order they arrived.
+func WithFairness(fair bool) Option {
+	return func(s *Service) {
+		s.fair = fair
+	}
+}
+
+// fairSemaphore is a semaphore granting its slots by priority and in FIFO order for the same priority, safe for
+// concurrent use.
+type fairSemaphore struct {
+	// size is the number of slots.
+	size int
+
+	// mu guards the fields below.
+	mu sync.Mutex
+	// cur is the number of slots taken.
+	cur int
+	// waiters is the queue of the callers waiting for a slot, sorted by descending priority and by arrival. Each one
+	// waits for the ready channel of its waiter to be closed.
+	waiters list.List
+	// depths is the number of waiters by priority.
+	depths map[int]int
+}
+
+// waiter is a caller waiting for a slot of a fairSemaphore.
+type waiter struct {
+	ready    chan struct{}
+	priority int
+}
+
+// newFairSemaphore creates a fair semaphore with the given number of slots.
+func newFairSemaphore(size int) *fairSemaphore {
+	return &fairSemaphore{size: size, depths: make(map[int]int)}
+}
+
+// Acquire takes a slot with the default priority 0. See AcquirePriority.
+func (s *fairSemaphore) Acquire(ctx context.Context) error {
+	return s.AcquirePriority(ctx, 0)
+}
+
+// AcquirePriority takes a slot, blocking until all the callers with a higher priority and the callers with the same
+// priority that arrived earlier have taken theirs and a slot frees up.
+// If the context gets cancelled while waiting, the caller leaves the queue and the context error is returned.
+func (s *fairSemaphore) AcquirePriority(ctx context.Context, priority int) error {
+	s.mu.Lock()
+	if s.cur < s.size && s.waiters.Len() == 0 {
+		s.cur++
+		s.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	w := &waiter{ready: ready, priority: priority}
+	// Queue behind the last waiter with the same or a higher priority.
+	var elem *list.Element
+	for e := s.waiters.Back(); e != nil; e = e.Prev() {
+		if e.Value.(*waiter).priority >= priority {
+			elem = s.waiters.InsertAfter(w, e)
+			break
+		}
+	}
+	if elem == nil {
+		elem = s.waiters.PushFront(w)
+	}
+	s.depths[priority]++
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-ready:
+			// The slot was granted in the meantime, so pass it on.
+			s.releaseLocked()
+		default:
+			s.remove(elem)
+		}
+		return contextErr(ctx)
+	}
+}
+
+// Release frees a slot taken by Acquire, handing it over to the first waiting caller, if there is one.
+func (s *fairSemaphore) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.releaseLocked()
+}
+
+// releaseLocked frees a slot. It must be called with mu held.
+func (s *fairSemaphore) releaseLocked() {
+	front := s.waiters.Front()
+	if front == nil {
+		s.cur--
+		return
+	}
+	// The slot is handed over, so the number of slots taken remains the same.
+	s.remove(front)
+	close(front.Value.(*waiter).ready)
+}
+
+// remove removes the waiter from the queue. It must be called with mu held.
+func (s *fairSemaphore) remove(e *list.Element) {
+	w := s.waiters.Remove(e).(*waiter)
+	if s.depths[w.priority]--; s.depths[w.priority] == 0 {
+		delete(s.depths, w.priority)
+	}
+}
+
+// Depths returns the number of callers waiting for a slot by priority.
+func (s *fairSemaphore) Depths() map[int]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	depths := make(map[int]int, len(s.depths))
+	for p, n := range s.depths {
+		depths[p] = n
+	}
+
+	return depths
+}
+
+// Len returns the number of slots taken.
+func (s *fairSemaphore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cur
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test case for the slots being granted in arrival order
+func TestFairSemaphore_Acquire_Order(t *testing.T) {
+	sem := newFairSemaphore(1)
+	if err := sem.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() got err %v, wanted %v", err, nil)
+	}
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := sem.Acquire(context.Background()); err != nil {
+				t.Errorf("Acquire() got err %v, wanted %v", err, nil)
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			sem.Release()
+		}(i)
+		// Make sure that the callers arrive in order.
+		waitFor(t, func() bool {
+			sem.mu.Lock()
+			defer sem.mu.Unlock()
+			return sem.waiters.Len() == i+1
+		})
+	}
+	sem.Release()
+	wg.Wait()
+
+	wanted := []int{0, 1, 2, 3, 4}
+	if !reflect.DeepEqual(order, wanted) {
+		t.Errorf("got grants in order %v, wanted %v", order, wanted)
+	}
+	if sem.Len() != 0 {
+		t.Errorf("Len() got %d, wanted %d", sem.Len(), 0)
+	}
+}
+
+// Test case for a caller leaving the queue when its context gets cancelled
+func TestFairSemaphore_Acquire_Cancelled(t *testing.T) {
+	sem := newFairSemaphore(1)
+	sem.Acquire(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := sem.Acquire(ctx)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if sem.waiters.Len() != 0 {
+		t.Errorf("got %d waiters, wanted %d", sem.waiters.Len(), 0)
+	}
+	sem.Release()
+	if sem.Len() != 0 {
+		t.Errorf("Len() got %d, wanted %d", sem.Len(), 0)
+	}
+}
+
+// Test case for the fair concurrency limit of the Service
+func TestService_Serve_Fairness(t *testing.T) {
+	release := make(chan struct{})
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		<-release
+		return Response{Data: req.Data}, nil
+	}, WithFairness(true), WithMaxConcurrency(1))
+
+	first := srv.ServeAsync(context.Background(), Request{Data: "first"})
+	waitFor(t, func() bool { return srv.InFlight() == 1 })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := srv.Serve(ctx, Request{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+	if response, err := first.Wait(); err != nil || response.Data != "first" {
+		t.Errorf("Wait() got %v, %v, wanted %v, %v", response, err, Response{Data: "first"}, nil)
+	}
+	waitFor(t, func() bool { return srv.InFlight() == 0 })
+}
+
+// Test case for the priority of the waiting callers. A high priority call waiting behind low priority calls gets the
+// next freed slot, while the calls with the same priority keep their order.
+func TestService_Serve_Priority(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		mu.Lock()
+		order = append(order, req.Data)
+		mu.Unlock()
+		<-release
+		return Response{}, nil
+	}, WithMaxConcurrency(1), WithFairness(true))
+
+	var wg sync.WaitGroup
+	serve := func(req Request, wantDepths map[int]int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			srv.Serve(context.Background(), req)
+		}()
+		waitFor(t, func() bool { return reflect.DeepEqual(srv.QueueDepths(), wantDepths) })
+	}
+	serve(Request{Data: "holder"}, map[int]int{})
+	waitFor(t, func() bool { return srv.InFlight() == 1 })
+	serve(Request{Data: "low 1"}, map[int]int{0: 1})
+	serve(Request{Data: "low 2"}, map[int]int{0: 2})
+	serve(Request{Data: "lowest", Priority: -1}, map[int]int{0: 2, -1: 1})
+	serve(Request{Data: "high", Priority: 10}, map[int]int{0: 2, -1: 1, 10: 1})
+
+	close(release)
+	wg.Wait()
+
+	wanted := []string{"holder", "high", "low 1", "low 2", "lowest"}
+	if !reflect.DeepEqual(order, wanted) {
+		t.Errorf("got slots granted in order %v, wanted %v", order, wanted)
+	}
+	if depths := srv.QueueDepths(); len(depths) != 0 {
+		t.Errorf("QueueDepths() got %v, wanted no waiters", depths)
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+)
+
+// WithFallback is an option that serves a degraded response instead of returning an error.
+// When Serve fails for any reason, including the context being cancelled or exceeding its deadline, the fallback
+// is called with the original error as cause, and its return values become the return values of Serve.
+// The fallback respects the remaining budget of the context: while the context is not done, the fallback runs in
+// its own goroutine and Serve returns the context error if the context gets cancelled first. When the context is
+// already done (e.g. the work timed out) there is no budget left, so the fallback is called directly and it is
+// expected to return without blocking, e.g. by serving a stale response from a cache.
+func WithFallback(fallback func(ctx context.Context, req Request, cause error) (Response, error)) Option {
+	return func(s *Service) {
+		s.fallback = fallback
+	}
+}
+
+// fallBack calls the fallback within the remaining budget of the context.
+func (s *Service) fallBack(ctx context.Context, req Request, cause error) (Response, error) {
+	if ctx.Err() != nil {
+		return s.fallback(ctx, req, cause)
+	}
+
+	// Use buffered channel to avoid goroutine leak in case the context gets cancelled.
+	resultCh := resultChan(1)
+	go func(req Request) {
+		res, err := s.fallback(ctx, req, cause)
+		resultCh <- result{res: res, err: err}
+	}(withOwnMeta(req))
+
+	select {
+	case r := <-resultCh:
+		return r.res, r.err
+	case <-ctx.Done():
+		return Response{}, contextErr(ctx)
+	}
+}
+
+// WithDegradationLadder is an option that generalizes WithFallback to an ordered list of fallbacks, e.g. a cheaper
+// secondary source and then a static default: when the work fails, the stages are called in order until one of them
+// succeeds. Every stage receives the error of the previous stage, or of the work for the first stage, and the error
+// of the last stage is returned if they all fail. Like WithFallback, which it replaces, the original error of the
+// work is the cause of ServeResult and LogEvent.
+// The ladder respects the remaining budget of the context like WithFallback: if the context expires while the
+// stages are running, Serve returns the context error and the rest of the stages are not called. When the context
+// is already done (e.g. the work timed out), every stage is tried in turn, so the stages are expected to return
+// without blocking in that case, e.g. by checking the context first.
+func WithDegradationLadder(stages ...func(ctx context.Context, req Request, prevErr error) (Response, error)) Option {
+	return func(s *Service) {
+		if len(stages) == 0 {
+			s.fallback = nil
+			return
+		}
+		s.fallback = func(ctx context.Context, req Request, cause error) (Response, error) {
+			// Stop climbing down the ladder only if the context expires while it is climbed.
+			expired := ctx.Err() != nil
+			var res Response
+			err := cause
+			for _, stage := range stages {
+				if !expired && ctx.Err() != nil {
+					return Response{}, contextErr(ctx)
+				}
+				if res, err = stage(ctx, req, err); err == nil {
+					return res, nil
+				}
+			}
+
+			return Response{}, err
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test case for a failing work. The fallback receives the work error and its response is returned.
+func TestService_Serve_FallbackOnError(t *testing.T) {
+	workErr := errors.New("error")
+	var gotCause error
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, workErr
+	}, WithFallback(func(ctx context.Context, req Request, cause error) (Response, error) {
+		gotCause = cause
+		return Response{Data: "fallback"}, nil
+	}))
+
+	response, err := srv.Serve(context.Background(), Request{})
+
+	if err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+	wantResp := Response{Data: "fallback"}
+	if !reflect.DeepEqual(response, wantResp) {
+		t.Errorf("Serve() got response %v, wanted %v", response, wantResp)
+	}
+	if gotCause != workErr {
+		t.Errorf("fallback got cause %v, wanted %v", gotCause, workErr)
+	}
+}
+
+// Test case for a timeout. The fallback receives context.DeadlineExceeded and its response is returned.
+func TestService_Serve_FallbackOnTimeout(t *testing.T) {
+	var gotCause error
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		time.Sleep(2000 * time.Millisecond)
+		return Response{Data: "success"}, nil
+	}, WithFallback(func(ctx context.Context, req Request, cause error) (Response, error) {
+		gotCause = cause
+		return Response{Data: "stale"}, nil
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	response, err := srv.Serve(ctx, Request{})
+
+	if err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+	wantResp := Response{Data: "stale"}
+	if !reflect.DeepEqual(response, wantResp) {
+		t.Errorf("Serve() got response %v, wanted %v", response, wantResp)
+	}
+	if !errors.Is(gotCause, context.DeadlineExceeded) {
+		t.Errorf("fallback got cause %v, wanted %v", gotCause, context.DeadlineExceeded)
+	}
+}
+
+// Test case for a slow fallback. The fallback respects the remaining budget of the context.
+func TestService_Serve_FallbackRespectsBudget(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, errors.New("error")
+	}, WithFallback(func(ctx context.Context, req Request, cause error) (Response, error) {
+		time.Sleep(2000 * time.Millisecond)
+		return Response{Data: "fallback"}, nil
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 1000*time.Millisecond {
+		t.Errorf("Serve() returned after %v, wanted the fallback to respect the deadline", elapsed)
+	}
+}
+
+// Test case for the cause of a successful fallback. The original timeout is exposed in the ServeResult and in the
+// LogEvent, even though the fallback succeeded and no error is returned.
+func TestService_ServeDetailed_FallbackCause(t *testing.T) {
+	var event LogEvent
+	ts := &TestService{DelayReponse: time.Second}
+	srv := NewServiceWithOptions(ts.Serve,
+		WithTimeout(10*time.Millisecond),
+		WithFallback(func(ctx context.Context, req Request, cause error) (Response, error) {
+			return Response{Data: "fallback"}, nil
+		}),
+		WithLogger(func(ctx context.Context, e LogEvent) {
+			event = e
+		}))
+
+	result := srv.ServeDetailed(context.Background(), Request{})
+
+	if result.Err != nil || result.Response.Data != "fallback" {
+		t.Errorf("ServeDetailed() got response %v and err %v, wanted the fallback response", result.Response, result.Err)
+	}
+	if !errors.Is(result.Cause, context.DeadlineExceeded) {
+		t.Errorf("ServeDetailed() got cause %v, wanted %v", result.Cause, context.DeadlineExceeded)
+	}
+	if event.Err != nil || !errors.Is(event.Cause, context.DeadlineExceeded) {
+		t.Errorf("got event with err %v and cause %v, wanted no err and cause %v", event.Err, event.Cause,
+			context.DeadlineExceeded)
+	}
+
+	result = NewServiceWithOptions(noopWork).ServeDetailed(context.Background(), Request{})
+	if result.Cause != nil {
+		t.Errorf("ServeDetailed() got cause %v, wanted %v", result.Cause, nil)
+	}
+}
+
+// Test case for the degradation ladder. The first two stages fail and the third one succeeds, every stage seeing the
+// error of the previous one.
+func TestService_Serve_DegradationLadder(t *testing.T) {
+	workErr, secondaryErr, cacheErr := errors.New("work"), errors.New("secondary"), errors.New("cache")
+	var gotErrs []error
+	stage := func(res Response, err error) func(ctx context.Context, req Request, prevErr error) (Response, error) {
+		return func(ctx context.Context, req Request, prevErr error) (Response, error) {
+			gotErrs = append(gotErrs, prevErr)
+			return res, err
+		}
+	}
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, workErr
+	}, WithDegradationLadder(
+		stage(Response{}, secondaryErr),
+		stage(Response{}, cacheErr),
+		stage(Response{Data: "default"}, nil),
+	))
+
+	result := srv.ServeDetailed(context.Background(), Request{})
+
+	if result.Err != nil || result.Response.Data != "default" {
+		t.Errorf("ServeDetailed() got %v and err %v, wanted %v", result.Response, result.Err, Response{Data: "default"})
+	}
+	if want := []error{workErr, secondaryErr, cacheErr}; !reflect.DeepEqual(gotErrs, want) {
+		t.Errorf("stages got errors %v, wanted %v", gotErrs, want)
+	}
+	if result.Cause != workErr {
+		t.Errorf("ServeDetailed() got cause %v, wanted %v", result.Cause, workErr)
+	}
+}
+
+// Test case for a degradation ladder whose stages all fail. The error of the last stage is returned.
+func TestService_Serve_DegradationLadderExhausted(t *testing.T) {
+	lastErr := errors.New("last")
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, errors.New("work")
+	}, WithDegradationLadder(
+		func(ctx context.Context, req Request, prevErr error) (Response, error) {
+			return Response{}, errors.New("first")
+		},
+		func(ctx context.Context, req Request, prevErr error) (Response, error) {
+			return Response{}, lastErr
+		},
+	))
+
+	if _, err := srv.Serve(context.Background(), Request{}); err != lastErr {
+		t.Errorf("Serve() got err %v, wanted %v", err, lastErr)
+	}
+}
+
+// Test case for the context expiring while the ladder is climbed. The rest of the stages are not called.
+func TestService_Serve_DegradationLadderExpired(t *testing.T) {
+	var thirdCalled atomic.Bool
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, errors.New("work")
+	}, WithDegradationLadder(
+		func(ctx context.Context, req Request, prevErr error) (Response, error) {
+			<-ctx.Done()
+			return Response{}, ctx.Err()
+		},
+		func(ctx context.Context, req Request, prevErr error) (Response, error) {
+			thirdCalled.Store(true)
+			return Response{Data: "default"}, nil
+		},
+	))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := srv.Serve(ctx, Request{})
+	// Give the ladder the time to call the next stage, if it would.
+	time.Sleep(10 * time.Millisecond)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if thirdCalled.Load() {
+		t.Errorf("the stage after the expiration was called, wanted no call")
+	}
+}
+
+// Test case for the Meta of a slow fallback. The fallback writes its own copy of the Meta while the logger reads the
+// Meta of the request after the context has expired.
+func TestService_Serve_FallbackRequestMeta(t *testing.T) {
+	written := make(chan struct{})
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, errors.New("error")
+	}, WithFallback(func(ctx context.Context, req Request, cause error) (Response, error) {
+		defer close(written)
+		<-ctx.Done()
+		for i := 0; i < 100; i++ {
+			req.SetMeta("fallback", i)
+		}
+		return Response{Data: "fallback"}, nil
+	}), WithLogger(func(ctx context.Context, event LogEvent) {
+		for range 100 {
+			_ = event.Request.Meta["tier"]
+		}
+	}))
+	meta := map[string]any{"tier": "premium"}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := srv.Serve(ctx, Request{Meta: meta})
+	<-written
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if want := map[string]any{"tier": "premium"}; !reflect.DeepEqual(meta, want) {
+		t.Errorf("the caller got meta %v, wanted %v", meta, want)
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// Fingerprint returns a stable key of the request, the same for identical requests across processes and restarts,
+// so that it can be used as the key of an external cache (see WithCacheBackend). It is the default key of WithCache
+// and WithSingleFlight, unless WithKeyFunc is used.
+// The key is the hex encoded SHA-256 hash of the fields identifying the request. Body, Budget, Meta and Priority are
+// not part of it, since they are a stream, a value set by the Service, scratch space and a scheduling hint
+// respectively.
+func Fingerprint(req Request) string {
+	h := sha256.New()
+	// Every field is prefixed with its length, so that the boundaries of the fields are part of the hash and the
+	// fields added in the future don't collide with the existing ones.
+	writeField := func(b []byte) {
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(b)))
+		h.Write(size[:])
+		h.Write(b)
+	}
+	writeField([]byte(req.Data))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// WithKeyFunc is an option that sets the key of the requests for every option that needs one (WithCache,
+// WithSingleFlight and WithKeyedRateLimit), unless the option is given its own key function. Requests with the same
+// key are considered identical. A nil keyFn uses Fingerprint, which is the default.
+func WithKeyFunc(keyFn func(Request) string) Option {
+	return func(s *Service) {
+		s.keyFn = keyFn
+	}
+}
+
+// useKeyFunc hands the key function of the Service over to the options without their own key function. It is
+// called after all the options are applied, since WithKeyFunc may come after them.
+func (s *Service) useKeyFunc() {
+	keyFn := s.keyFn
+	if keyFn == nil {
+		keyFn = Fingerprint
+	}
+	if s.cache != nil && s.cache.keyFn == nil {
+		s.cache.keyFn = keyFn
+	}
+	if s.flights != nil && s.flights.keyFn == nil {
+		s.flights.keyFn = keyFn
+	}
+	if s.keyedLimiter != nil && s.keyedLimiter.keyFn == nil {
+		s.keyedLimiter.keyFn = keyFn
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test case for Fingerprint. Identical requests get identical fingerprints and different requests get different
+// ones, while the fields that don't identify a request are ignored.
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Request
+		same bool
+	}{
+		{name: "identical", a: Request{Data: "a"}, b: Request{Data: "a"}, same: true},
+		{name: "empty", a: Request{}, b: Request{}, same: true},
+		{name: "different data", a: Request{Data: "a"}, b: Request{Data: "b"}, same: false},
+		{name: "empty and non empty", a: Request{}, b: Request{Data: " "}, same: false},
+		{name: "ignored fields", a: Request{Data: "a"}, b: Request{
+			Data:   "a",
+			Body:   strings.NewReader("body"),
+			Budget: time.Second,
+			Meta:   map[string]any{"key": "value"},
+		}, same: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := Fingerprint(tt.a), Fingerprint(tt.b)
+			if (a == b) != tt.same {
+				t.Errorf("Fingerprint() got %q and %q, wanted same %v", a, b, tt.same)
+			}
+		})
+	}
+}
+
+// Test case for WithKeyFunc. It is used by the options without their own key function, whatever the order of the
+// options is.
+func TestService_Serve_KeyFunc(t *testing.T) {
+	ts := &TestService{Synchronous: true}
+	// The requests are identical regardless of the case of their data.
+	srv := NewServiceWithOptions(ts.Serve, WithCache(time.Minute, nil), WithKeyFunc(func(req Request) string {
+		return strings.ToLower(req.Data)
+	}))
+
+	srv.Serve(context.Background(), Request{Data: "a"})
+	srv.Serve(context.Background(), Request{Data: "A"})
+
+	if stats := srv.CacheStats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("CacheStats() got %+v, wanted 1 hit and 1 miss", stats)
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"runtime/debug"
+)
+
+// GenericServer is the generic variant of the Server interface, for services with typed requests and responses.
+type GenericServer[Req, Res any] interface {
+	Serve(ctx context.Context, req Req) (Res, error)
+}
+
+// GenericService is the generic variant of Service, for work with typed requests and responses instead of the
+// sample Request and Response types, so that there is no need to marshal everything into strings.
+// It serves the request with the same goroutine and select as Service, over a channel of typed results, so it
+// behaves exactly like Service, e.g. a panic of the work is returned as a *PanicError and the cause of the
+// cancellation of the context is kept, but it has none of the optional features of Service.
+type GenericService[Req, Res any] struct {
+	// func representing the actual work that needs to be done in order to calculate the response.
+	// It receives the context and the request passed to Serve.
+	work func(ctx context.Context, req Req) (Res, error)
+}
+
+// NewGenericService is a factory function/constructor for the GenericService. It panics if the work is nil, instead
+// of failing later in Serve.
+func NewGenericService[Req, Res any](work func(ctx context.Context, req Req) (Res, error)) *GenericService[Req, Res] {
+	if work == nil {
+		panic(ErrNilWork.Error())
+	}
+
+	return &GenericService[Req, Res]{
+		work: work,
+	}
+}
+
+// Serve is the method of the GenericService that handles the request.
+// It responds back with a response on the happy path or an error in case of failure.
+func (s *GenericService[Req, Res]) Serve(ctx context.Context, req Req) (Res, error) {
+	// Use buffered channel to avoid goroutine leak in case the context gets cancelled
+	resultCh := typedResultChan[Res](1)
+
+	go func() {
+		// Do the work and send its outcome in the resultCh
+		res, err := s.do(ctx, req)
+		resultCh <- typedResult[Res]{res: res, err: err}
+	}()
+	// Select will block until the resultCh receives the outcome of the work or the context is cancelled
+	var zero Res
+	select {
+	case r := <-resultCh:
+		if r.err != nil {
+			return zero, r.err
+		}
+		return r.res, nil
+	case <-ctx.Done():
+		return zero, contextErr(ctx)
+	}
+}
+
+// do calls the work, converting a panic of the work to a *PanicError, like Service does by default.
+func (s *GenericService[Req, Res]) do(ctx context.Context, req Req) (res Res, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return s.work(ctx, req)
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type user struct {
+	ID   int
+	Name string
+}
+
+// Test case for the happy path of a typed service.
+func TestGenericService_Serve_Success(t *testing.T) {
+	srv := NewGenericService(func(ctx context.Context, id int) (user, error) {
+		return user{ID: id, Name: "gopher"}, nil
+	})
+
+	response, err := srv.Serve(context.Background(), 42)
+
+	if err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+	wantResp := user{ID: 42, Name: "gopher"}
+	if !reflect.DeepEqual(response, wantResp) {
+		t.Errorf("Serve() got response %v, wanted %v", response, wantResp)
+	}
+}
+
+// Test case for a failing typed service.
+func TestGenericService_Serve_Error(t *testing.T) {
+	wantErr := errors.New("error")
+	srv := NewGenericService(func(ctx context.Context, id int) (user, error) {
+		return user{ID: id}, wantErr
+	})
+
+	response, err := srv.Serve(context.Background(), 42)
+
+	if err != wantErr {
+		t.Errorf("Serve() got err %v, wanted %v", err, wantErr)
+	}
+	if !reflect.DeepEqual(response, user{}) {
+		t.Errorf("Serve() got response %v, wanted %v", response, user{})
+	}
+}
+
+// Test case for a typed service timeout.
+func TestGenericService_Serve_Timeout(t *testing.T) {
+	srv := NewGenericService(func(ctx context.Context, id int) (user, error) {
+		time.Sleep(2000 * time.Millisecond)
+		return user{ID: id}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := srv.Serve(ctx, 42)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+}
+
+// Test case for the typed test service. It records the typed request and the context error.
+func TestGenericTestService_Serve(t *testing.T) {
+	var srv GenericServer[int, user] = &GenericTestService[int, user]{
+		Res:          user{ID: 1},
+		DelayReponse: time.Second,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	srv.Serve(ctx, 42)
+
+	recorder := srv.(*GenericTestService[int, user]).Recorder
+	if recorder.Request != 42 {
+		t.Errorf("got request %v, wanted %v", recorder.Request, 42)
+	}
+	if !recorder.CtxDeadlineExceeded {
+		t.Errorf("CtxDeadlineExceeded should be true")
+	}
+}
+
+// Test case for a typed service whose work panics. The panic is returned as an error, like by Service.
+func TestGenericService_Serve_Panic(t *testing.T) {
+	srv := NewGenericService(func(ctx context.Context, id int) (user, error) {
+		panic("boom")
+	})
+
+	_, err := srv.Serve(context.Background(), 42)
+
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Value != "boom" {
+		t.Errorf("Serve() got err %v, wanted a %T with value %v", err, pe, "boom")
+	}
+}
+
+// Test case for a typed service whose context gets cancelled with a cause. The cause is kept, like by Service.
+func TestGenericService_Serve_CancelCause(t *testing.T) {
+	cause := errors.New("client went away")
+	srv := NewGenericService(func(ctx context.Context, id int) (user, error) {
+		<-ctx.Done()
+		return user{}, ctx.Err()
+	})
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(cause)
+
+	_, err := srv.Serve(ctx, 42)
+
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, cause) {
+		t.Errorf("Serve() got err %v, wanted %v and %v", err, context.Canceled, cause)
+	}
+}
+
+// Test case for the typed test service called in parallel. It records like TestService, with the responses picked
+// by call and the recorder safe to read with Snapshot.
+func TestGenericTestService_ServeParallel(t *testing.T) {
+	srv := &GenericTestService[int, user]{Responses: []user{{ID: 1}}, Res: user{ID: 2}, Synchronous: true}
+
+	first, err := srv.Serve(context.Background(), 1)
+	if err != nil || first != (user{ID: 1}) {
+		t.Errorf("Serve() got (%v, %v), wanted (%v, %v)", first, err, user{ID: 1}, nil)
+	}
+	done := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		go func() {
+			srv.Serve(context.Background(), 42)
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		srv.Snapshot()
+		<-done
+	}
+
+	r := srv.Snapshot()
+	if r.Calls != 11 || r.Request != 42 || r.ReturnedResponse != (user{ID: 2}) {
+		t.Errorf("got %d calls, request %v and response %v, wanted %d, %v and %v", r.Calls, r.Request,
+			r.ReturnedResponse, 11, 42, user{ID: 2})
+	}
+	srv.AssertServed(t, 42)
+}
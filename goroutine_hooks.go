@@ -0,0 +1,14 @@
+package service
+
+// WithGoroutineHooks is an option that calls onStart when the goroutine running the work of a request starts, and
+// onEnd when it ends, after the work has returned, even if Serve has already returned because the context was done.
+// Each hook is called exactly once per launched work, so they can be used for custom accounting, e.g. a gauge of
+// the goroutines in flight, without the metrics of WithMetrics. With WithWorkerPool the hooks are called by the
+// worker running the work. The hooks are called synchronously by the goroutine of the work, so they must be cheap
+// and safe for concurrent use. Any of them can be nil.
+func WithGoroutineHooks(onStart func(), onEnd func()) Option {
+	return func(s *Service) {
+		s.onGoroutineStart = onStart
+		s.onGoroutineEnd = onEnd
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test case for the goroutine hooks, fired exactly once per call of Serve, whether the work returns in time or not
+func TestService_Serve_GoroutineHooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		wantErr error
+	}{
+		{name: "in time", delay: 0, wantErr: nil},
+		{name: "timeout", delay: 50 * time.Millisecond, wantErr: context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var starts, ends int64
+			// The work ignores the context, so it keeps running after the timeout.
+			srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+				time.Sleep(tt.delay)
+				return Response{}, nil
+			}, WithTimeout(10*time.Millisecond), WithGoroutineHooks(
+				func() { atomic.AddInt64(&starts, 1) },
+				func() { atomic.AddInt64(&ends, 1) },
+			))
+
+			_, err := srv.Serve(context.Background(), Request{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Serve() got err %v, wanted %v", err, tt.wantErr)
+			}
+			if err := srv.Close(context.Background()); err != nil {
+				t.Fatalf("Close() got err %v, wanted %v", err, nil)
+			}
+
+			if got := atomic.LoadInt64(&starts); got != 1 {
+				t.Errorf("onStart got %d calls, wanted %d", got, 1)
+			}
+			if got := atomic.LoadInt64(&ends); got != 1 {
+				t.Errorf("onEnd got %d calls, wanted %d", got, 1)
+			}
+		})
+	}
+}
+
+// Test case for nil hooks. Serve doesn't panic.
+func TestService_Serve_GoroutineHooksNil(t *testing.T) {
+	srv := NewServiceWithOptions((&TestService{}).Serve, WithGoroutineHooks(nil, nil))
+
+	if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+		t.Errorf("Serve() got err %v, wanted %v", err, nil)
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import "context"
+
+// WithHealthProbe is an option that replaces the work with the probe in Healthy, e.g. a ping of the downstream
+// service that is cheaper than the actual work.
+func WithHealthProbe(probe func(ctx context.Context) error) Option {
+	return func(s *Service) {
+		s.healthProbe = probe
+	}
+}
+
+// Healthy reports whether the Service can serve requests, e.g. for a Kubernetes readiness check.
+// It calls the health probe if there is one (see WithHealthProbe), otherwise the work with an empty Request, and
+// returns nil on success. The probe bypasses the cache, the circuit breaker, the retries and every other option of
+// Serve, so that it reflects the true health of the downstream service.
+// Pass a context with a short timeout, about 1 second and well below the timeout of the readiness check, so that a
+// hanging downstream service makes the probe fail instead of hanging too. Healthy returns the context error as soon
+// as the context gets cancelled, along with the cause of the cancellation if there is one, even if the probe ignores
+// the context.
+// Healthy returns ErrClosed after Close has been called.
+func (s *Service) Healthy(ctx context.Context) error {
+	if err := s.enter(); err != nil {
+		return err
+	}
+	defer s.inflight.Done()
+
+	// Use buffered channel to avoid goroutine leak in case the context gets cancelled
+	errCh := make(chan error, 1)
+	s.inflight.Add(1)
+	go func() {
+		defer s.inflight.Done()
+		errCh <- s.probe(ctx)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return contextErr(ctx)
+	}
+}
+
+// probe calls the health probe, or the work if there is no health probe, converting a panic to an error.
+func (s *Service) probe(ctx context.Context) (err error) {
+	defer s.recoverWork(&err)
+
+	if s.healthProbe != nil {
+		return s.healthProbe(ctx)
+	}
+	_, err = s.work(ctx, Request{})
+
+	return err
+}
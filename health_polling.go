@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// HealthChecker is an optional interface of the backends that can report their health, like Service does with
+// Healthy. The backends of a Balancer implementing it are polled by StartHealthPolling.
+type HealthChecker interface {
+	Healthy(ctx context.Context) error
+}
+
+// WithHealthCallback is an option that calls fn every time the health polling of the Balancer (see
+// StartHealthPolling) finds that a backend became unhealthy or healthy again, e.g. for logging or alerting.
+func WithHealthCallback(fn func(server Server, healthy bool)) BalancerOption {
+	return func(b *Balancer) {
+		b.onHealthChange = fn
+	}
+}
+
+// StartHealthPolling starts polling the backends implementing HealthChecker every interval, in the background, until
+// the context is cancelled. A backend whose Healthy returns an error is skipped like a failing backend (see Balancer)
+// until a later poll finds it healthy again. Every check is bounded by the interval, so that a hanging backend
+// doesn't stall the polling.
+// The interval is jittered by up to 20% in either direction, so that many Balancers started together don't poll the
+// backends in sync. Values lower or equal to zero don't start the polling.
+func (b *Balancer) StartHealthPolling(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.clock.After(jitterInterval(interval)):
+			}
+			for _, be := range b.backends {
+				if ctx.Err() != nil {
+					return
+				}
+				b.poll(ctx, be, interval)
+			}
+		}
+	}()
+}
+
+// poll checks the health of the backend, if it implements HealthChecker, and calls the health callback if it
+// changed.
+func (b *Balancer) poll(ctx context.Context, be *backend, timeout time.Duration) {
+	checker, ok := be.Server.(HealthChecker)
+	if !ok {
+		return
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	err := checker.Healthy(checkCtx)
+	cancel()
+	// A check interrupted by the end of the polling says nothing about the backend.
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+	healthy := err == nil
+
+	b.mu.Lock()
+	changed := be.down == healthy
+	be.down = !healthy
+	b.mu.Unlock()
+
+	if changed && b.onHealthChange != nil {
+		b.onHealthChange(be.Server, healthy)
+	}
+}
+
+// jitterInterval returns a random duration in [0.8*interval, 1.2*interval].
+func jitterInterval(interval time.Duration) time.Duration {
+	spread := 2 * interval / 5
+	if spread <= 0 {
+		return interval
+	}
+
+	return interval - spread/2 + rand.N(spread+1)
+}
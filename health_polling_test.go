@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flappingServer is a Server whose health checks return the errors of healthErrs in turn.
+type flappingServer struct {
+	TestService
+
+	mu         sync.Mutex
+	healthErrs []error
+	checks     int
+}
+
+// Healthy returns the next error of healthErrs.
+func (f *flappingServer) Healthy(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	err := f.healthErrs[f.checks%len(f.healthErrs)]
+	f.checks++
+
+	return err
+}
+
+// Test case for the health polling of a Balancer. The callback is called on every transition of a flapping backend,
+// and the backend is skipped while it is down.
+func TestBalancer_StartHealthPolling(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	flapping := &flappingServer{
+		TestService: TestService{Res: Response{Data: "flapping"}, Synchronous: true},
+		healthErrs:  []error{errors.New("down"), nil, nil, errors.New("down")},
+	}
+	stable := &TestService{Res: Response{Data: "stable"}, Synchronous: true}
+
+	var mu sync.Mutex
+	var transitions []bool
+	b := NewBalancer([]Backend{{Server: flapping, Weight: 1}, {Server: stable, Weight: 1}},
+		WithBalancerClock(clock),
+		WithHealthCallback(func(server Server, healthy bool) {
+			if server != flapping {
+				t.Errorf("callback got server %v, wanted the flapping backend", server)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			transitions = append(transitions, healthy)
+		}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b.StartHealthPolling(ctx, time.Second)
+
+	want := []bool{false, true, true, false}
+	wantTransitions := [][]bool{{false}, {false, true}, {false, true}, {false, true, false}}
+	for i := range want {
+		clock.BlockUntil(1)
+		clock.Advance(2 * time.Second)
+		waitFor(t, func() bool {
+			flapping.mu.Lock()
+			defer flapping.mu.Unlock()
+			return flapping.checks == i+1
+		})
+		waitFor(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(transitions) == len(wantTransitions[i])
+		})
+		if !want[i] {
+			for range 3 {
+				if res, _ := b.Serve(context.Background(), Request{}); res.Data != "stable" {
+					t.Errorf("Serve() got response %v, wanted the stable backend while the flapping one is down", res)
+				}
+			}
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, healthy := range wantTransitions[len(wantTransitions)-1] {
+		if transitions[i] != healthy {
+			t.Errorf("transition %d got healthy %v, wanted %v", i, transitions[i], healthy)
+		}
+	}
+}
+
+// Test case for stopping the health polling. No more checks are made once the context is cancelled.
+func TestBalancer_StartHealthPolling_Stop(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	flapping := &flappingServer{healthErrs: []error{nil}}
+	b := NewBalancer([]Backend{{Server: flapping}}, WithBalancerClock(clock))
+	ctx, cancel := context.WithCancel(context.Background())
+	b.StartHealthPolling(ctx, time.Second)
+
+	clock.BlockUntil(1)
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	clock.Advance(2 * time.Second)
+	time.Sleep(10 * time.Millisecond)
+
+	flapping.mu.Lock()
+	defer flapping.mu.Unlock()
+	if flapping.checks != 0 {
+		t.Errorf("got %d checks after the polling stopped, wanted 0", flapping.checks)
+	}
+}
+
+// Test case for the jitter of the polling interval. It stays within 20% of the interval.
+func TestJitterInterval(t *testing.T) {
+	for range 1000 {
+		if d := jitterInterval(time.Second); d < 800*time.Millisecond || d > 1200*time.Millisecond {
+			t.Fatalf("jitterInterval() got %v, wanted within [800ms, 1.2s]", d)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test case for the health check calling the work, bypassing the cache and the circuit breaker
+func TestService_Healthy_Work(t *testing.T) {
+	workErr := errors.New("error")
+	ts := &TestService{Errs: []error{nil, workErr, workErr}, Res: Response{Data: "success"}}
+	srv := NewServiceWithOptions(ts.Serve, WithCache(time.Minute, nil), WithCircuitBreaker(1, time.Minute))
+
+	if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+		t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+	}
+	if err := srv.Healthy(context.Background()); !errors.Is(err, workErr) {
+		t.Errorf("Healthy() got err %v, wanted %v", err, workErr)
+	}
+	if err := srv.Healthy(context.Background()); !errors.Is(err, workErr) {
+		t.Errorf("Healthy() got err %v, wanted %v", err, workErr)
+	}
+	if ts.Recorder.Calls != 3 {
+		t.Errorf("got %d calls, wanted %d", ts.Recorder.Calls, 3)
+	}
+	if state := srv.CircuitBreaker().State(); state != CircuitClosed {
+		t.Errorf("got circuit state %q, wanted %q", state, CircuitClosed)
+	}
+}
+
+// Test case for the health check calling the health probe instead of the work
+func TestService_Healthy_Probe(t *testing.T) {
+	ts := &TestService{Err: errors.New("error")}
+	probed := false
+	srv := NewServiceWithOptions(ts.Serve, WithHealthProbe(func(ctx context.Context) error {
+		probed = true
+		return nil
+	}))
+
+	if err := srv.Healthy(context.Background()); err != nil {
+		t.Errorf("Healthy() got err %v, wanted %v", err, nil)
+	}
+	if !probed {
+		t.Errorf("the health probe was not called")
+	}
+	if ts.Recorder.Calls != 0 {
+		t.Errorf("got %d calls, wanted %d", ts.Recorder.Calls, 0)
+	}
+}
+
+// Test case for the health check of a hanging work, ignoring the context
+func TestService_Healthy_Timeout(t *testing.T) {
+	srv := NewService(func() (Response, error) {
+		time.Sleep(time.Second)
+		return Response{}, nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := srv.Healthy(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Healthy() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+}
+
+// Test case for the health check of a closed Service
+func TestService_Healthy_Closed(t *testing.T) {
+	srv := NewService(func() (Response, error) {
+		return Response{}, nil
+	})
+	srv.Close(context.Background())
+
+	if err := srv.Healthy(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("Healthy() got err %v, wanted %v", err, ErrClosed)
+	}
+}
+
+// Test case for the health check of a hanging work whose context gets cancelled with a cause
+func TestService_Healthy_CancelCause(t *testing.T) {
+	cause := errors.New("shutting down")
+	srv := NewService(func() (Response, error) {
+		time.Sleep(time.Second)
+		return Response{}, nil
+	})
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(10*time.Millisecond, func() { cancel(cause) })
+
+	if err := srv.Healthy(ctx); !errors.Is(err, context.Canceled) || !errors.Is(err, cause) {
+		t.Errorf("Healthy() got err %v, wanted %v and %v", err, context.Canceled, cause)
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// WithHedging is an option that reduces the tail latency of idempotent work by launching extra (hedged) copies of
+// the work when it is slow. If the work doesn't return within delay another copy is launched, up to maxHedges extra
+// copies, one every delay. The first successful response wins and the context of the rest of the copies gets
+// cancelled. Failed copies don't launch new ones, so the error of the last copy is returned only when every
+// launched copy has failed. Use WithRetry for retrying errors. The bodies of the responses of the losing copies are
+// released, and Close waits for the losing copies to return.
+// The work must be idempotent, since the same request may be served more than once.
+func WithHedging(delay time.Duration, maxHedges int) Option {
+	return func(s *Service) {
+		s.hedgeDelay = delay
+		s.maxHedges = maxHedges
+	}
+}
+
+// Hedges returns the number of hedged copies of the work launched by the Service, not counting the original calls.
+func (s *Service) Hedges() int {
+	return int(atomic.LoadInt64(&s.hedges))
+}
+
+// settleHedges waits in the background for the rest of the running copies to return, releasing the bodies of their
+// responses, since nobody is going to read them. If there is a winning response, it is compared with the successful
+// responses of the rest of the copies, when there is a mismatch handler. See WithMismatchHandler.
+func (s *Service) settleHedges(req Request, winner *Response, results <-chan result, running int) {
+	if running == 0 {
+		return
+	}
+
+	s.inflight.Add(1)
+	go func() {
+		defer s.inflight.Done()
+
+		for ; running > 0; running-- {
+			r := <-results
+			if winner != nil && r.err == nil && s.onMismatch != nil {
+				s.compareResponses(req, *winner, r.res)
+			}
+			DrainAndClose(r.res.Body)
+		}
+	}()
+}
+
+// hedge calls the work, launching hedged copies according to the hedging options.
+func (s *Service) hedge(ctx context.Context, req Request) (Response, error) {
+	if s.maxHedges <= 0 {
+		return s.call(ctx, req)
+	}
+
+	// Cancel the copies that are still running when the first one wins.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The original call and every hedged copy may send, so the abandoned ones never block.
+	results := resultChan(s.maxHedges + 1)
+	// Track every copy until it returns, even after a winner, so that Close can wait for it.
+	launch := func() {
+		s.inflight.Add(1)
+		copyReq := withOwnMeta(req)
+		go func() {
+			defer s.inflight.Done()
+			res, err := s.call(ctx, copyReq)
+			results <- result{res: res, err: err}
+		}()
+	}
+
+	launch()
+	running, launched := 1, 1
+	timer := s.clock.NewTimer(s.hedgeDelay)
+	defer timer.Stop()
+
+	var err error
+	for {
+		select {
+		case r := <-results:
+			running--
+			if r.err == nil {
+				s.settleHedges(req, &r.res, results, running)
+				return r.res, nil
+			}
+			err = r.err
+			if running == 0 {
+				return Response{}, err
+			}
+		case <-timer.C():
+			if launched <= s.maxHedges {
+				atomic.AddInt64(&s.hedges, 1)
+				launch()
+				running++
+				launched++
+				timer.Reset(s.hedgeDelay)
+			}
+		case <-ctx.Done():
+			s.settleHedges(req, nil, results, running)
+			return Response{}, contextErr(ctx)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test case for a slow first call. The hedged copy returns first and wins.
+func TestService_Serve_HedgingWins(t *testing.T) {
+	var calls int64
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			select {
+			case <-time.After(2000 * time.Millisecond):
+			case <-ctx.Done():
+			}
+			return Response{Data: "slow"}, nil
+		}
+		return Response{Data: "fast"}, nil
+	}, WithHedging(20*time.Millisecond, 2))
+
+	start := time.Now()
+	response, err := srv.Serve(context.Background(), Request{})
+
+	if err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+	wantResp := Response{Data: "fast"}
+	if !reflect.DeepEqual(response, wantResp) {
+		t.Errorf("Serve() got response %v, wanted %v", response, wantResp)
+	}
+	if elapsed := time.Since(start); elapsed > 1000*time.Millisecond {
+		t.Errorf("Serve() returned after %v, wanted the hedged copy to win", elapsed)
+	}
+	if srv.Hedges() != 1 {
+		t.Errorf("Hedges() got %d, wanted %d", srv.Hedges(), 1)
+	}
+}
+
+// Test case for a fast work. No hedged copy is launched.
+func TestService_Serve_HedgingNotNeeded(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: "fast"}, nil
+	}, WithHedging(100*time.Millisecond, 2))
+
+	if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+	if srv.Hedges() != 0 {
+		t.Errorf("Hedges() got %d, wanted %d", srv.Hedges(), 0)
+	}
+}
+
+// Test case for a work that is always slow. No more than maxHedges copies are launched, and no goroutine leaks
+// after the abandoned copies return.
+func TestService_Serve_HedgingMaxHedges(t *testing.T) {
+	before := runtime.NumGoroutine()
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		time.Sleep(100 * time.Millisecond)
+		return Response{Data: "slow"}, nil
+	}, WithHedging(10*time.Millisecond, 2))
+
+	if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+	if srv.Hedges() != 2 {
+		t.Errorf("Hedges() got %d, wanted %d", srv.Hedges(), 2)
+	}
+	if srv.Attempts() != 3 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 3)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("got %d goroutines after the hedged copies returned, wanted at most %d", after, before)
+	}
+}
+
+// Test case for a losing copy still running after the winner returned. Close waits for it, and its body is released.
+func TestService_Serve_HedgingLoserTracked(t *testing.T) {
+	var calls int64
+	release := make(chan struct{})
+	loserBody := &trackedBody{Reader: strings.NewReader("slow")}
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			// The first call ignores the context, so it is still running after the hedged copy wins.
+			<-release
+			return Response{Data: "slow", Body: loserBody}, nil
+		}
+		return Response{Data: "fast"}, nil
+	}, WithHedging(10*time.Millisecond, 1))
+
+	if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+		t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+	}
+	closed := make(chan error, 1)
+	go func() { closed <- srv.Close(context.Background()) }()
+
+	select {
+	case err := <-closed:
+		t.Fatalf("Close() returned %v while the losing copy was running", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if err := <-closed; err != nil {
+		t.Errorf("Close() got err %v, wanted %v", err, nil)
+	}
+	if !loserBody.isClosed() {
+		t.Errorf("the body of the losing copy was not released")
+	}
+}
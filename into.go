@@ -0,0 +1,17 @@
+package service
+
+import "context"
+
+// ServeInto serves the request like Serve, writing the response into out on success and leaving out untouched on
+// error, so that the callers of hot paths can reuse a Response instead of receiving a new one by value every time.
+// out must not be nil.
+func (s *Service) ServeInto(ctx context.Context, req Request, out *Response) error {
+	var d details
+	res, err := s.run(ctx, req, &d)
+	if err != nil {
+		return err
+	}
+	*out = res
+
+	return nil
+}
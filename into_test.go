@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Test case for ServeInto, writing the response on success and leaving it untouched on error
+func TestService_ServeInto(t *testing.T) {
+	wantErr := errors.New("error")
+	tests := []struct {
+		name    string
+		ts      *TestService
+		wantErr error
+		want    Response
+	}{
+		{name: "success", ts: &TestService{Res: Response{Data: "response"}}, want: Response{Data: "response"}},
+		{name: "error", ts: &TestService{Res: Response{Data: "response"}, Err: wantErr}, wantErr: wantErr, want: Response{Data: "untouched"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServiceWithOptions(tt.ts.Serve)
+			out := Response{Data: "untouched"}
+
+			err := srv.ServeInto(context.Background(), Request{}, &out)
+
+			if err != tt.wantErr {
+				t.Errorf("ServeInto() got err %v, wanted %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(out, tt.want) {
+				t.Errorf("ServeInto() got response %v, wanted %v", out, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WithKeyedRateLimit is an option that limits the requests served per second for every key, e.g. per tenant, using
+// a token bucket per key that holds up to burst tokens and is refilled with rps tokens per second, so that a key
+// reaching its limit doesn't throttle the rest of the keys. keyFn returns the key of a request. A nil keyFn uses
+// the key function of WithKeyFunc, or Fingerprint by default.
+// The buckets of the keys that have been idle long enough to be full again, with no request waiting for a token, are
+// evicted, since they are identical to the bucket of a new key, so the memory is bounded by the number of the active
+// keys.
+// It applies on top of WithRateLimit, and it behaves according to the same mode (see WithRateLimitMode).
+// A rps lower or equal to 0 disables the limit.
+func WithKeyedRateLimit(rps int, burst int, keyFn func(Request) string) Option {
+	return func(s *Service) {
+		if rps <= 0 {
+			s.keyedLimiter = nil
+			return
+		}
+		s.keyedLimiter = newKeyedLimiter(float64(rps), burst, keyFn)
+	}
+}
+
+// RateLimitTokens returns the number of tokens available for the key in the limiter of WithKeyedRateLimit, e.g. for
+// testing. A key without requests has a full bucket. It returns 0 without WithKeyedRateLimit.
+func (s *Service) RateLimitTokens(key string) float64 {
+	if s.keyedLimiter == nil {
+		return 0
+	}
+
+	return s.keyedLimiter.available(key)
+}
+
+// limitKey consumes a token of the bucket of the request key, if there is a keyed rate limiter, according to the rate
+// limit mode.
+func (s *Service) limitKey(ctx context.Context, req Request) error {
+	if s.keyedLimiter == nil {
+		return nil
+	}
+	b := s.keyedLimiter.bucket(s.keyedLimiter.keyFn(req))
+	defer s.keyedLimiter.release(b)
+	if s.limiterMode == RateLimitReject {
+		if !b.allow() {
+			return ErrRateLimited
+		}
+		return nil
+	}
+
+	if err := b.wait(ctx); err != nil {
+		return fmt.Errorf("service: waiting for the rate limiter: %w", err)
+	}
+
+	return nil
+}
+
+// keyedLimiter holds a token bucket per key, safe for concurrent use.
+type keyedLimiter struct {
+	rate  float64
+	burst int
+	keyFn func(Request) string
+	// idle is the time after which an unused bucket is full again, so it can be evicted.
+	idle time.Duration
+	// clock is the source of time, set to the clock of the Service.
+	clock Clock
+
+	// mu guards the fields below.
+	mu      sync.Mutex
+	buckets map[string]*keyedBucket
+	// swept is the last time the idle buckets were evicted.
+	swept time.Time
+}
+
+// keyedBucket is the token bucket of a key along with its usage. Its fields are guarded by the mu of the limiter.
+type keyedBucket struct {
+	*tokenBucket
+	// lastUsed is the last time a request was done with the bucket, e.g. once it consumed a token.
+	lastUsed time.Time
+	// users is the number of requests using the bucket, including the ones waiting for a token.
+	users int
+}
+
+// newKeyedLimiter creates a keyed limiter without buckets. A burst lower than 1 is treated as 1.
+func newKeyedLimiter(rate float64, burst int, keyFn func(Request) string) *keyedLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &keyedLimiter{
+		rate:    rate,
+		burst:   burst,
+		keyFn:   keyFn,
+		idle:    time.Duration(float64(burst) / rate * float64(time.Second)),
+		clock:   realClock{},
+		buckets: make(map[string]*keyedBucket),
+		swept:   time.Now(),
+	}
+}
+
+// bucket returns the bucket of the key, creating a full one if there is none, and evicts the idle buckets once
+// every idle period. A bucket in use, e.g. by a request waiting for a token, is never evicted. The bucket must be
+// released once the request is done with it.
+func (l *keyedLimiter) bucket(key string) *keyedBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	if now.Sub(l.swept) >= l.idle {
+		for k, b := range l.buckets {
+			if b.users == 0 && now.Sub(b.lastUsed) >= l.idle {
+				delete(l.buckets, k)
+			}
+		}
+		l.swept = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &keyedBucket{tokenBucket: newTokenBucket(l.rate, l.burst)}
+		b.setClock(l.clock)
+		l.buckets[key] = b
+	}
+	b.users++
+	b.lastUsed = now
+
+	return b
+}
+
+// release marks the request done with the bucket, so the idle period of the bucket starts over.
+func (l *keyedLimiter) release(b *keyedBucket) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b.users--
+	b.lastUsed = l.clock.Now()
+}
+
+// available returns the number of tokens available for the key, without creating its bucket.
+func (l *keyedLimiter) available(key string) float64 {
+	l.mu.Lock()
+	b, ok := l.buckets[key]
+	l.mu.Unlock()
+	if !ok {
+		return float64(l.burst)
+	}
+
+	return b.available()
+}
+
+// len returns the number of buckets.
+func (l *keyedLimiter) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
+
+// setClock replaces the source of time.
+func (l *keyedLimiter) setClock(clock Clock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.clock = clock
+	l.swept = clock.Now()
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test case for the independent buckets of the keys. A throttled key doesn't affect the rest of the keys.
+func TestService_Serve_KeyedRateLimit(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	srv := NewServiceWithOptions(noopWork, WithClock(clock), WithRateLimitMode(RateLimitReject),
+		WithKeyedRateLimit(1, 2, func(req Request) string {
+			return req.Data
+		}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := srv.Serve(context.Background(), Request{Data: "a"}); err != nil {
+			t.Errorf("Serve() got err %v, wanted %v", err, nil)
+		}
+	}
+	if _, err := srv.Serve(context.Background(), Request{Data: "a"}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Serve() got err %v, wanted %v", err, ErrRateLimited)
+	}
+	if tokens := srv.RateLimitTokens("b"); tokens != 2 {
+		t.Errorf("RateLimitTokens() got %v tokens for the idle key, wanted %v", tokens, 2)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := srv.Serve(context.Background(), Request{Data: "b"}); err != nil {
+			t.Errorf("Serve() got err %v for the other key, wanted %v", err, nil)
+		}
+	}
+	if tokens := srv.RateLimitTokens("a"); tokens != 0 {
+		t.Errorf("RateLimitTokens() got %v tokens, wanted %v", tokens, 0)
+	}
+
+	clock.Advance(time.Second)
+	if tokens := srv.RateLimitTokens("a"); tokens != 1 {
+		t.Errorf("RateLimitTokens() got %v tokens after the refill, wanted %v", tokens, 1)
+	}
+}
+
+// Test case for the block mode of the keyed rate limit with a context cancelled while waiting. Serve returns the
+// context error.
+func TestService_Serve_KeyedRateLimitCancelled(t *testing.T) {
+	srv := NewServiceWithOptions(noopWork, WithKeyedRateLimit(1, 1, nil))
+	srv.Serve(context.Background(), Request{Data: "a"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := srv.Serve(ctx, Request{Data: "a"})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if _, err := srv.Serve(context.Background(), Request{Data: "b"}); err != nil {
+		t.Errorf("Serve() got err %v for the other key, wanted %v", err, nil)
+	}
+}
+
+// Test case for the eviction of the idle buckets. The buckets full again are evicted, bounding the memory.
+func TestService_Serve_KeyedRateLimitEviction(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	srv := NewServiceWithOptions(noopWork, WithClock(clock), WithKeyedRateLimit(1, 2, func(req Request) string {
+		return req.Data
+	}))
+
+	srv.Serve(context.Background(), Request{Data: "a"})
+	srv.Serve(context.Background(), Request{Data: "b"})
+	if n := srv.keyedLimiter.len(); n != 2 {
+		t.Errorf("got %d buckets, wanted %d", n, 2)
+	}
+
+	clock.Advance(2 * time.Second)
+	srv.Serve(context.Background(), Request{Data: "c"})
+
+	if n := srv.keyedLimiter.len(); n != 1 {
+		t.Errorf("got %d buckets after the idle period, wanted %d", n, 1)
+	}
+}
+
+// Test case for the eviction of a bucket with a request waiting for a token. The bucket is in use, so it is not
+// evicted, and the next request of the key doesn't get a fresh bucket exceeding the limit.
+func TestService_Serve_KeyedRateLimitEvictionWaiting(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	srv := NewServiceWithOptions(noopWork, WithClock(clock), WithKeyedRateLimit(1, 1, func(req Request) string {
+		return req.Data
+	}))
+	srv.Serve(context.Background(), Request{Data: "a"})
+
+	served := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := srv.Serve(context.Background(), Request{Data: "a"})
+			served <- err
+		}()
+	}
+	clock.BlockUntil(2)
+	// One of the waiting requests gets the token, while the other one waits for the next one.
+	clock.Advance(time.Second)
+	if err := <-served; err != nil {
+		t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+	}
+	// Sweep the idle buckets.
+	srv.Serve(context.Background(), Request{Data: "b"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := srv.Serve(ctx, Request{Data: "a"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	clock.Advance(time.Second)
+	if err := <-served; err != nil {
+		t.Errorf("Serve() got err %v for the waiting request, wanted %v", err, nil)
+	}
+}
@@ -0,0 +1,16 @@
+package service
+
+import "time"
+
+// WithLateResultHandler is an option that calls handler with the outcome of the work that returned after Serve
+// stopped waiting for it because the context was done, along with how long after that the work returned.
+// It helps surfacing work that routinely overruns its deadline, which otherwise goes unnoticed.
+// The handler is called from a background goroutine, never from the goroutine of the work, so a slow handler doesn't
+// keep the work running. The handler may be called concurrently for different requests, so it must be safe for
+// concurrent use. The body of the response, if any, is closed after the handler returns.
+// With WithMetrics the delays are recorded in a histogram too, with or without a handler.
+func WithLateResultHandler(handler func(res Response, err error, lateBy time.Duration)) Option {
+	return func(s *Service) {
+		s.lateResultHandler = handler
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test case for a slow work returning after the timeout. The handler gets the outcome of the work and how late it was.
+func TestService_Serve_LateResultHandler(t *testing.T) {
+	type late struct {
+		res    Response
+		err    error
+		lateBy time.Duration
+	}
+	lateCh := make(chan late, 1)
+	// The work ignores the context, like the work that overruns its deadline.
+	work := func(ctx context.Context, req Request) (Response, error) {
+		time.Sleep(100 * time.Millisecond)
+		return Response{Data: "late"}, nil
+	}
+	srv := NewServiceWithOptions(work, WithLateResultHandler(func(res Response, err error, lateBy time.Duration) {
+		lateCh <- late{res: res, err: err, lateBy: lateBy}
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	select {
+	case got := <-lateCh:
+		if got.res.Data != "late" || got.err != nil {
+			t.Errorf("handler got (%v, %v), wanted (%v, %v)", got.res, got.err, Response{Data: "late"}, nil)
+		}
+		if got.lateBy <= 0 || got.lateBy > time.Second {
+			t.Errorf("handler got lateBy %v, wanted about %v", got.lateBy, 90*time.Millisecond)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler was not called")
+	}
+}
+
+// Test case for a work returning in time. The handler is not called.
+func TestService_Serve_LateResultHandlerInTime(t *testing.T) {
+	called := make(chan struct{}, 1)
+	srv := NewServiceWithOptions((&TestService{}).Serve, WithLateResultHandler(func(Response, error, time.Duration) {
+		called <- struct{}{}
+	}))
+
+	if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+		t.Errorf("Serve() got err %v, wanted %v", err, nil)
+	}
+	if err := srv.Close(context.Background()); err != nil {
+		t.Fatalf("Close() got err %v, wanted %v", err, nil)
+	}
+
+	select {
+	case <-called:
+		t.Errorf("handler should not be called")
+	default:
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// LeastConnBalancer is a Server routing every request to the backend with the fewest requests in flight, so that
+// a slow backend gets fewer requests than a fast one, reducing the tail latency when the response times of the
+// backends vary. Ties are broken in round-robin order. It is safe for concurrent use.
+type LeastConnBalancer struct {
+	servers []Server
+
+	// mu guards the fields below.
+	mu sync.Mutex
+	// inflight is the number of requests in flight by backend.
+	inflight []int
+	// next is the index of the backend to check first, so that the ties are broken in round-robin order.
+	next int
+}
+
+// NewLeastConnBalancer is a factory function/constructor for a LeastConnBalancer routing the requests to the
+// servers. Calling Serve on a LeastConnBalancer without servers returns ErrNoServers.
+func NewLeastConnBalancer(servers ...Server) *LeastConnBalancer {
+	return &LeastConnBalancer{
+		servers:  servers,
+		inflight: make([]int, len(servers)),
+	}
+}
+
+// Serve sends the request to the least loaded backend and returns its response and error, wrapped with the name of
+// the backend if it has one (see Named).
+func (b *LeastConnBalancer) Serve(ctx context.Context, req Request) (Response, error) {
+	if len(b.servers) == 0 {
+		return Response{}, ErrNoServers
+	}
+
+	i := b.acquire()
+	// Decrement the count even if the backend panics.
+	defer b.release(i)
+
+	res, err := b.servers[i].Serve(ctx, req)
+
+	return res, withServerName(b.servers[i], err)
+}
+
+// InFlight returns the number of requests in flight by backend, in the order of the servers of
+// NewLeastConnBalancer.
+func (b *LeastConnBalancer) InFlight() []int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]int(nil), b.inflight...)
+}
+
+// acquire picks the least loaded backend and counts the request as in flight on it.
+func (b *LeastConnBalancer) acquire() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	picked := b.next
+	for j := 1; j < len(b.servers); j++ {
+		if i := (b.next + j) % len(b.servers); b.inflight[i] < b.inflight[picked] {
+			picked = i
+		}
+	}
+	b.next = (picked + 1) % len(b.servers)
+	b.inflight[picked]++
+
+	return picked
+}
+
+// release counts the request as no longer in flight on the backend.
+func (b *LeastConnBalancer) release(i int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.inflight[i]--
+}
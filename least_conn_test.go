@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Test case for a saturated backend. The new requests go to the idle one until the saturated one recovers.
+func TestLeastConnBalancer_Serve_Saturated(t *testing.T) {
+	release := make(chan struct{})
+	slow := ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+		<-release
+		return Response{Data: "slow"}, nil
+	})
+	fast := &TestService{Synchronous: true, Res: Response{Data: "fast"}}
+	b := NewLeastConnBalancer(slow, fast)
+
+	// The first request goes to the slow backend and saturates it.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.Serve(context.Background(), Request{})
+	}()
+	waitFor(t, func() bool { return b.InFlight()[0] == 1 })
+
+	for i := 0; i < 5; i++ {
+		res, err := b.Serve(context.Background(), Request{})
+		if err != nil || res.Data != "fast" {
+			t.Errorf("Serve() got (%v, %v), wanted (%v, %v)", res, err, Response{Data: "fast"}, nil)
+		}
+	}
+
+	close(release)
+	<-done
+	if got, want := b.InFlight(), []int{0, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("InFlight() got %v, wanted %v", got, want)
+	}
+}
+
+// Test case for a backend timing out or panicking. Its in flight count is decremented anyway.
+func TestLeastConnBalancer_Serve_Release(t *testing.T) {
+	b := NewLeastConnBalancer(
+		&TestService{DelayReponse: time.Minute},
+		ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+			panic("boom")
+		}),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	b.Serve(ctx, Request{})
+	func() {
+		defer func() { recover() }()
+		b.Serve(context.Background(), Request{})
+	}()
+
+	if got, want := b.InFlight(), []int{0, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("InFlight() got %v, wanted %v", got, want)
+	}
+}
+
+// Test case for a LeastConnBalancer without servers
+func TestLeastConnBalancer_Serve_NoServers(t *testing.T) {
+	if _, err := NewLeastConnBalancer().Serve(context.Background(), Request{}); err != ErrNoServers {
+		t.Errorf("Serve() got err %v, wanted %v", err, ErrNoServers)
+	}
+}
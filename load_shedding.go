@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrOverloaded is the error returned by Serve when the request is shed by the load shedding (see WithLoadShedding).
+var ErrOverloaded = errors.New("service: overloaded")
+
+const (
+	// sheddingSamples is the number of recent work durations that the load shedding keeps.
+	sheddingSamples = 100
+	// sheddingWindow is the maximum age of the work durations that the load shedding takes into account, so that the
+	// rejections stop once the slow calls are old enough, even if no call was served since then.
+	sheddingWindow = 10 * time.Second
+)
+
+// WithLoadShedding is an option that rejects a fraction of the requests with ErrOverloaded, without launching the
+// work, when the 99th percentile of the recent work durations exceeds latencyThreshold, in order to protect a
+// degraded downstream from collapsing under the load.
+// The reject ratio grows linearly from 0 when the 99th percentile is at latencyThreshold, up to maxRejectRatio when it
+// is twice the latencyThreshold or more. Only the last 100 work durations of the last 10 seconds are taken into
+// account. The current reject ratio is returned by RejectRatio.
+func WithLoadShedding(latencyThreshold time.Duration, maxRejectRatio float64) Option {
+	return func(s *Service) {
+		s.shedder = &loadShedder{
+			threshold: latencyThreshold,
+			maxRatio:  maxRejectRatio,
+			clock:     realClock{},
+		}
+	}
+}
+
+// RejectRatio returns the fraction of the requests currently rejected by the load shedding, from 0 to the
+// maxRejectRatio of WithLoadShedding. It is always 0 without WithLoadShedding.
+func (s *Service) RejectRatio() float64 {
+	if s.shedder == nil {
+		return 0
+	}
+
+	return s.shedder.ratio()
+}
+
+// shed returns ErrOverloaded if the request is picked for rejection by the load shedding, if there is one.
+func (s *Service) shed() error {
+	if s.shedder == nil {
+		return nil
+	}
+	if ratio := s.shedder.ratio(); ratio > 0 && rand.Float64() < ratio {
+		return ErrOverloaded
+	}
+
+	return nil
+}
+
+// sample is a work duration recorded by the load shedding.
+type sample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// loadShedder keeps the recent work durations and computes the reject ratio, safe for concurrent use.
+type loadShedder struct {
+	threshold time.Duration
+	maxRatio  float64
+	// clock is the source of time, set to the clock of the Service.
+	clock Clock
+
+	// mu guards the fields below.
+	mu sync.Mutex
+	// samples is a ring buffer of the recent work durations.
+	samples [sheddingSamples]sample
+	// next is the index of samples to write next.
+	next int
+	// n is the number of samples written, up to sheddingSamples.
+	n int
+}
+
+// record records the duration of a call of the work.
+func (l *loadShedder) record(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.samples[l.next] = sample{at: l.clock.Now(), duration: d}
+	l.next = (l.next + 1) % sheddingSamples
+	if l.n < sheddingSamples {
+		l.n++
+	}
+}
+
+// ratio returns the current reject ratio.
+func (l *loadShedder) ratio() float64 {
+	l.mu.Lock()
+	durations := make([]time.Duration, 0, l.n)
+	since := l.clock.Now().Add(-sheddingWindow)
+	for _, smp := range l.samples[:l.n] {
+		if smp.at.After(since) {
+			durations = append(durations, smp.duration)
+		}
+	}
+	l.mu.Unlock()
+
+	if len(durations) == 0 || l.threshold <= 0 {
+		return 0
+	}
+	slices.Sort(durations)
+	p99 := durations[(len(durations)*99-1)/100]
+	if p99 <= l.threshold {
+		return 0
+	}
+
+	return l.maxRatio * min(1, float64(p99-l.threshold)/float64(l.threshold))
+}
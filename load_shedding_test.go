@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Test case for a work slower than the latency threshold. Some of the following calls get ErrOverloaded, until the
+// slow calls are old enough.
+func TestService_Serve_LoadShedding(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	delay := 100 * time.Millisecond
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		clock.Advance(delay)
+		return Response{}, nil
+	}, WithLoadShedding(50*time.Millisecond, 0.5), WithClock(clock))
+
+	if ratio := srv.RejectRatio(); ratio != 0 {
+		t.Errorf("RejectRatio() got %v, wanted %v", ratio, 0)
+	}
+	if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+		t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+	}
+	if ratio := srv.RejectRatio(); ratio != 0.5 {
+		t.Errorf("RejectRatio() got %v, wanted %v", ratio, 0.5)
+	}
+
+	// Make the rest of the calls fast, so that only the rejections are affected by the first call.
+	delay = 0
+	overloaded := 0
+	for i := 0; i < 200; i++ {
+		if _, err := srv.Serve(context.Background(), Request{}); err == ErrOverloaded {
+			overloaded++
+		}
+	}
+	if overloaded == 0 || overloaded == 200 {
+		t.Errorf("got %d calls with err %v out of %d, wanted some of them", overloaded, ErrOverloaded, 200)
+	}
+
+	clock.Advance(sheddingWindow)
+	if ratio := srv.RejectRatio(); ratio != 0 {
+		t.Errorf("RejectRatio() got %v, wanted %v", ratio, 0)
+	}
+}
+
+func TestLoadShedder_Ratio(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		want     float64
+	}{
+		{name: "below threshold", duration: 50 * time.Millisecond, want: 0},
+		{name: "half way", duration: 150 * time.Millisecond, want: 0.4},
+		{name: "twice the threshold", duration: 200 * time.Millisecond, want: 0.8},
+		{name: "beyond twice the threshold", duration: time.Second, want: 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &loadShedder{threshold: 100 * time.Millisecond, maxRatio: 0.8, clock: realClock{}}
+			l.record(tt.duration)
+			if got := l.ratio(); got != tt.want {
+				t.Errorf("ratio() got %v, wanted %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import "sync"
+
+// WithLogSampling is an option that logs only a fraction rate of the successful requests (see WithLogger), in order
+// to keep the volume of the logs manageable at high rates, while the failed requests, including the timeouts and the
+// cancellations, are always logged. The sampling is deterministic instead of random: with a rate of 0.25 exactly one
+// out of four successful requests is logged, starting from the fourth, so the tests are reproducible.
+// A rate of 1 or more logs every request and a rate of 0 or less logs only the failed requests.
+func WithLogSampling(rate float64) Option {
+	return func(s *Service) {
+		s.logSampler = &sampler{rate: rate}
+	}
+}
+
+// shouldLog reports whether the outcome of a request should be logged, according to the log sampling.
+func (s *Service) shouldLog(err error) bool {
+	if err != nil || s.logSampler == nil {
+		return true
+	}
+
+	return s.logSampler.sample()
+}
+
+// sampler picks a fraction of the events deterministically, safe for concurrent use.
+type sampler struct {
+	rate float64
+
+	// mu guards credit.
+	mu sync.Mutex
+	// credit accumulates rate for every event, and an event is picked every time it reaches 1.
+	credit float64
+}
+
+// sample reports whether the next event is picked.
+func (l *sampler) sample() bool {
+	if l.rate >= 1 {
+		return true
+	}
+	if l.rate <= 0 {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.credit += l.rate
+	if l.credit < 1 {
+		return false
+	}
+	l.credit--
+
+	return true
+}
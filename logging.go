@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// LogEvent describes the lifecycle of a served request. It is passed to the logger of WithLogger.
+type LogEvent struct {
+	// Request is the served request.
+	Request Request
+	// Duration is the time elapsed from the call of Serve until it returned.
+	Duration time.Duration
+	// QueueWait is the part of Duration spent waiting for the rate limiter and for a slot of the concurrency limit
+	// (see WithRateLimit and WithMaxConcurrency), before launching the work. A long QueueWait points to contention
+	// rather than slow work. It is 0 when the work is not launched, e.g. on a cache hit.
+	QueueWait time.Duration
+	// Outcome is the outcome of Serve (see the Outcome constants), or the class of the error when a custom
+	// classifier is used (see WithErrorClassifier).
+	Outcome string
+	// Err is the error returned by Serve, nil on success.
+	Err error
+	// RequestID is the request ID of the call, empty without WithRequestID.
+	RequestID string
+	// Name is the name of the Server that served the request, empty if it has none (see Named).
+	Name string
+	// Cause is the original error of the request when it was replaced by the fallback (see WithFallback), even if
+	// the fallback succeeded and Err is nil, so that the underlying failures can be tracked. It is nil otherwise.
+	Cause error
+}
+
+// WithLogger is an option that calls the logger once for every call of Serve, when it returns, whatever the
+// outcome is (success, error, timeout or cancellation). The logger receives the context passed to Serve and
+// is called synchronously, so it should be cheap. A nil logger disables logging.
+// Use SlogLogger to log with a *slog.Logger.
+func WithLogger(logger func(ctx context.Context, event LogEvent)) Option {
+	return func(s *Service) {
+		s.logger = logger
+	}
+}
+
+// SlogLogger adapts a *slog.Logger to the logger of WithLogger. Successful requests are logged at info level and
+// failed requests at error level.
+func SlogLogger(logger *slog.Logger) func(ctx context.Context, event LogEvent) {
+	return func(ctx context.Context, event LogEvent) {
+		level := slog.LevelInfo
+		attrs := []slog.Attr{
+			slog.String("request", event.Request.Data),
+			slog.Duration("duration", event.Duration),
+			slog.String("outcome", event.Outcome),
+		}
+		if event.Name != "" {
+			attrs = append(attrs, slog.String("name", event.Name))
+		}
+		if event.RequestID != "" {
+			attrs = append(attrs, slog.String("request_id", event.RequestID))
+		}
+		if event.Err != nil {
+			level = slog.LevelError
+			attrs = append(attrs, slog.String("error", event.Err.Error()))
+		}
+		if event.Cause != nil {
+			attrs = append(attrs, slog.String("cause", event.Cause.Error()))
+		}
+
+		logger.LogAttrs(ctx, level, "service: request served", attrs...)
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test case for the logger. It is called once for every outcome with the matching event.
+func TestService_Serve_Logger(t *testing.T) {
+	workErr := errors.New("error")
+	var mu sync.Mutex
+	var events []LogEvent
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		switch req.Data {
+		case "error":
+			return Response{}, workErr
+		case "slow":
+			<-ctx.Done()
+			return Response{}, ctx.Err()
+		}
+		return Response{Data: "success"}, nil
+	}, WithLogger(func(ctx context.Context, event LogEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}))
+
+	srv.Serve(context.Background(), Request{Data: "success"})
+	srv.Serve(context.Background(), Request{Data: "error"})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	srv.Serve(ctx, Request{Data: "slow"})
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	srv.Serve(ctx, Request{Data: "slow"})
+
+	tests := []struct {
+		request string
+		outcome string
+		err     error
+	}{
+		{request: "success", outcome: OutcomeSuccess, err: nil},
+		{request: "error", outcome: OutcomeError, err: workErr},
+		{request: "slow", outcome: OutcomeTimeout, err: context.DeadlineExceeded},
+		{request: "slow", outcome: OutcomeCancelled, err: context.Canceled},
+	}
+	if len(events) != len(tests) {
+		t.Fatalf("got %d events, wanted %d", len(events), len(tests))
+	}
+	for i, tt := range tests {
+		e := events[i]
+		if e.Request.Data != tt.request || e.Outcome != tt.outcome || !errors.Is(e.Err, tt.err) {
+			t.Errorf("got event %+v, wanted request %q, outcome %q and error %v", e, tt.request, tt.outcome, tt.err)
+		}
+	}
+	if events[2].Duration < 10*time.Millisecond {
+		t.Errorf("got duration %v, wanted at least %v", events[2].Duration, 10*time.Millisecond)
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := SlogLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	logger(context.Background(), LogEvent{
+		Request:  Request{Data: "request"},
+		Duration: time.Second,
+		Outcome:  OutcomeError,
+		Err:      errors.New("work error"),
+	})
+
+	got := buf.String()
+	for _, want := range []string{"level=ERROR", "request=request", "duration=1s", "outcome=error", `error="work error"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("SlogLogger() logged %q, wanted it to contain %q", got, want)
+		}
+	}
+}
+
+// Test case for the log sampling. Only a fraction of the successful requests is logged, while every failed request
+// and every timeout bypasses the sampling.
+func TestService_Serve_LogSampling(t *testing.T) {
+	var events []LogEvent
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		switch req.Data {
+		case "error":
+			return Response{}, errors.New("error")
+		case "slow":
+			<-ctx.Done()
+			return Response{}, ctx.Err()
+		}
+		return Response{}, nil
+	}, WithLogSampling(0.25), WithLogger(func(ctx context.Context, event LogEvent) {
+		events = append(events, event)
+	}))
+
+	for i := 0; i < 8; i++ {
+		srv.Serve(context.Background(), Request{Data: "success"})
+		srv.Serve(context.Background(), Request{Data: "error"})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	srv.Serve(ctx, Request{Data: "slow"})
+
+	counts := make(map[string]int)
+	for _, event := range events {
+		counts[event.Outcome]++
+	}
+	want := map[string]int{OutcomeSuccess: 2, OutcomeError: 8, OutcomeTimeout: 1}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("got events by outcome %v, wanted %v", counts, want)
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultMetricsPrefix is the prefix of the metric names when WithMetricsPrefix is not used.
+const DefaultMetricsPrefix = "service"
+
+// WithMetrics is an option that records Prometheus metrics for Serve, registered with the given registerer:
+//   - <prefix>_serve_duration_seconds, a histogram of the Serve durations.
+//   - <prefix>_serve_total, a counter of the served requests labeled by outcome (see the Outcome constants and
+//     WithErrorClassifier).
+//   - <prefix>_queue_wait_seconds, a histogram of the time spent waiting for the rate limiter and for a slot of
+//     the concurrency limit before launching the work (see LogEvent.QueueWait).
+//   - <prefix>_overrun_seconds, a histogram of how long after Serve stopped waiting for it the abandoned work
+//     returned, e.g. because its context exceeded the deadline (see WithLateResultHandler). Unlike
+//     <prefix>_serve_duration_seconds, which ends at the deadline, it shows how much work keeps running past it.
+//
+// With WithCache, the effectiveness of the cache is recorded too:
+//   - <prefix>_cache_hits_total, a counter of the requests served from the cache.
+//   - <prefix>_cache_misses_total, a counter of the requests not found in the cache.
+//   - <prefix>_cache_evictions_total, a counter of the expired entries evicted from the memory of the Service.
+//   - <prefix>_cache_entries, a gauge of the entries held in the memory of the Service, which doesn't include the
+//     entries of an external backend (see WithCacheBackend).
+//
+// The prefix is DefaultMetricsPrefix unless it is set with WithMetricsPrefix.
+// The metrics are registered when the Service is created, which panics if the registration fails, e.g. because
+// another Service has already registered metrics with the same names. Use UnregisterMetrics to unregister them.
+func WithMetrics(registerer prometheus.Registerer) Option {
+	return func(s *Service) {
+		s.metricsRegisterer = registerer
+	}
+}
+
+// WithMetricsPrefix is an option that sets the prefix of the metric names of WithMetrics, so that many Services
+// can register their metrics with the same registerer.
+func WithMetricsPrefix(prefix string) Option {
+	return func(s *Service) {
+		s.metricsPrefix = prefix
+	}
+}
+
+// UnregisterMetrics unregisters the metrics of WithMetrics from the registerer, e.g. for a clean test teardown.
+// It does nothing without WithMetrics.
+func (s *Service) UnregisterMetrics() {
+	if s.metrics == nil {
+		return
+	}
+
+	for _, c := range s.metrics.collectors() {
+		s.metricsRegisterer.Unregister(c)
+	}
+}
+
+// metrics holds the Prometheus metrics of a Service.
+type metrics struct {
+	duration  prometheus.Histogram
+	served    *prometheus.CounterVec
+	queueWait prometheus.Histogram
+	overrun   prometheus.Histogram
+	// cache holds the metrics of the cache, nil without WithCache.
+	cache *cacheMetrics
+}
+
+// cacheMetrics holds the Prometheus metrics of the cache of a Service.
+type cacheMetrics struct {
+	hits      prometheus.Counter
+	misses    prometheus.Counter
+	evictions prometheus.Counter
+	entries   prometheus.GaugeFunc
+}
+
+// newMetrics creates the metrics using the given prefix and registers them with the registerer, including the
+// metrics of the cache if it is not nil. It panics if the registration fails.
+func newMetrics(registerer prometheus.Registerer, prefix string, cache *responseCache) *metrics {
+	if prefix == "" {
+		prefix = DefaultMetricsPrefix
+	}
+
+	m := &metrics{
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    prefix + "_serve_duration_seconds",
+			Help:    "Duration of Serve in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		served: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: prefix + "_serve_total",
+			Help: "Number of served requests by outcome.",
+		}, []string{"outcome"}),
+		queueWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    prefix + "_queue_wait_seconds",
+			Help:    "Time spent waiting for the rate limiter and for a concurrency slot in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		overrun: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    prefix + "_overrun_seconds",
+			Help:    "Time from abandoning the work until it returned in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}),
+	}
+	if cache != nil {
+		m.cache = &cacheMetrics{
+			hits: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: prefix + "_cache_hits_total",
+				Help: "Number of requests served from the cache.",
+			}),
+			misses: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: prefix + "_cache_misses_total",
+				Help: "Number of requests not found in the cache.",
+			}),
+			evictions: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: prefix + "_cache_evictions_total",
+				Help: "Number of expired cache entries evicted from memory.",
+			}),
+			entries: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: prefix + "_cache_entries",
+				Help: "Number of cache entries held in memory.",
+			}, func() float64 {
+				return float64(cache.len())
+			}),
+		}
+	}
+	// Initialize every outcome, so that it is exported even before it happens.
+	for _, o := range []string{OutcomeSuccess, OutcomeError, OutcomeTimeout, OutcomeCancelled} {
+		m.served.WithLabelValues(o)
+	}
+	registerer.MustRegister(m.collectors()...)
+
+	return m
+}
+
+// collectors returns all the metrics.
+func (m *metrics) collectors() []prometheus.Collector {
+	collectors := []prometheus.Collector{m.duration, m.served, m.queueWait, m.overrun}
+	if m.cache != nil {
+		collectors = append(collectors, m.cache.hits, m.cache.misses, m.cache.evictions, m.cache.entries)
+	}
+
+	return collectors
+}
+
+// observe records the duration and the outcome of Serve.
+func (m *metrics) observe(d time.Duration, outcome string) {
+	m.duration.Observe(d.Seconds())
+	m.served.WithLabelValues(outcome).Inc()
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// Test case for the metrics of every outcome.
+func TestService_Serve_Metrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		switch req.Data {
+		case "error":
+			return Response{}, errors.New("error")
+		case "slow":
+			<-ctx.Done()
+			return Response{}, ctx.Err()
+		}
+		return Response{Data: "success"}, nil
+	}, WithMetrics(registry), WithMetricsPrefix("test"))
+	defer srv.UnregisterMetrics()
+
+	srv.Serve(context.Background(), Request{})
+	srv.Serve(context.Background(), Request{})
+	srv.Serve(context.Background(), Request{Data: "error"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	srv.Serve(ctx, Request{Data: "slow"})
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	srv.Serve(ctx, Request{Data: "slow"})
+
+	want := map[string]float64{
+		OutcomeSuccess:   2,
+		OutcomeError:     1,
+		OutcomeTimeout:   1,
+		OutcomeCancelled: 1,
+	}
+	for o, n := range want {
+		if got := testutil.ToFloat64(srv.metrics.served.WithLabelValues(o)); got != n {
+			t.Errorf("test_serve_total{outcome=%q} got %v, wanted %v", o, got, n)
+		}
+	}
+
+	count, err := testutil.GatherAndCount(registry, "test_serve_duration_seconds", "test_serve_total")
+	if err != nil {
+		t.Fatalf("GatherAndCount() returned error %v", err)
+	}
+	// One histogram and four outcomes.
+	if count != 5 {
+		t.Errorf("GatherAndCount() got %d metrics, wanted %d", count, 5)
+	}
+}
+
+// Test case for unregistering the metrics. A new Service can register metrics with the same names.
+func TestService_UnregisterMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	work := func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, nil
+	}
+
+	srv := NewServiceWithOptions(work, WithMetrics(registry))
+	srv.UnregisterMetrics()
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("NewServiceWithOptions() panicked with %v", r)
+		}
+	}()
+	srv = NewServiceWithOptions(work, WithMetrics(registry))
+	srv.UnregisterMetrics()
+}
+
+// Test case for the metrics of the cache after a mix of hits, misses and evictions.
+func TestService_Serve_CacheMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	clock := NewFakeClock(time.Now())
+	srv := NewServiceWithOptions(noopWork,
+		WithCache(time.Second, nil),
+		WithClock(clock),
+		WithMetrics(registry),
+		WithMetricsPrefix("test"))
+	defer srv.UnregisterMetrics()
+
+	srv.Serve(context.Background(), Request{Data: "a"})
+	srv.Serve(context.Background(), Request{Data: "a"})
+	srv.Serve(context.Background(), Request{Data: "b"})
+	clock.Advance(2 * time.Second)
+	// The expired entry of "a" is evicted and replaced, while the one of "b" is still in memory.
+	srv.Serve(context.Background(), Request{Data: "a"})
+	srv.Serve(context.Background(), Request{Data: "a"})
+
+	want := `
+# HELP test_cache_entries Number of cache entries held in memory.
+# TYPE test_cache_entries gauge
+test_cache_entries 2
+# HELP test_cache_evictions_total Number of expired cache entries evicted from memory.
+# TYPE test_cache_evictions_total counter
+test_cache_evictions_total 1
+# HELP test_cache_hits_total Number of requests served from the cache.
+# TYPE test_cache_hits_total counter
+test_cache_hits_total 2
+# HELP test_cache_misses_total Number of requests not found in the cache.
+# TYPE test_cache_misses_total counter
+test_cache_misses_total 3
+`
+	err := testutil.GatherAndCompare(registry, strings.NewReader(want), "test_cache_entries",
+		"test_cache_evictions_total", "test_cache_hits_total", "test_cache_misses_total")
+	if err != nil {
+		t.Errorf("GatherAndCompare() returned error %v", err)
+	}
+}
+
+// Test case for the overrun metric. Work returning after the deadline records how long after the deadline it
+// returned.
+func TestService_Serve_OverrunMetric(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		// The work ignores the context, so it overruns the deadline.
+		time.Sleep(60 * time.Millisecond)
+		return Response{}, nil
+	}, WithMetrics(registry), WithMetricsPrefix("test"))
+	defer srv.UnregisterMetrics()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := srv.Serve(ctx, Request{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	srv.Close(context.Background())
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() returned error %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() != "test_overrun_seconds" {
+			continue
+		}
+		h := f.GetMetric()[0].GetHistogram()
+		if h.GetSampleCount() != 1 || h.GetSampleSum() < 0.03 {
+			t.Errorf("test_overrun_seconds got count %d and sum %v, wanted %d and at least %v",
+				h.GetSampleCount(), h.GetSampleSum(), 1, 0.03)
+		}
+		return
+	}
+	t.Errorf("test_overrun_seconds was not gathered")
+}
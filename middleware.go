@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ServerFunc is an adapter to allow the use of ordinary functions as a Server, in the spirit of http.HandlerFunc.
+type ServerFunc func(ctx context.Context, req Request) (Response, error)
+
+// Serve calls f(ctx, req).
+func (f ServerFunc) Serve(ctx context.Context, req Request) (Response, error) {
+	return f(ctx, req)
+}
+
+// Middleware decorates a Server with a cross-cutting concern (retries, timeouts, logging etc), returning a Server
+// that handles the concern and calls the decorated Server.
+type Middleware func(next Server) Server
+
+// Chain decorates the Server with the middleware. The first middleware is the outermost one, so it is the first to
+// see the request and the last to see the response, e.g. Chain(s, a, b) is equivalent to a(b(s)).
+func Chain(s Server, mw ...Middleware) Server {
+	for i := len(mw) - 1; i >= 0; i-- {
+		s = mw[i](s)
+	}
+
+	return s
+}
+
+// RetryMiddleware retries the decorated Server when it returns an error, like WithRetry.
+// attempts is the maximum number of calls, including the first call, and backoff is the time to wait between two
+// attempts. The wait is interrupted as soon as the context gets cancelled, in which case the context error is
+// returned wrapped. If all the attempts fail, the error of the last attempt is returned unchanged.
+func RetryMiddleware(attempts int, backoff time.Duration) Middleware {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	return func(next Server) Server {
+		return ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+			var err error
+			for attempt := 0; attempt < attempts; attempt++ {
+				if attempt > 0 {
+					// Wait before the next attempt, unless the context gets cancelled in the meantime.
+					if ctxErr := sleep(ctx, realClock{}, backoff); ctxErr != nil {
+						return Response{}, fmt.Errorf("service: retry aborted after %d attempts: %w", attempt, ctxErr)
+					}
+				}
+				var res Response
+				res, err = next.Serve(ctx, req)
+				if err == nil {
+					return res, nil
+				}
+			}
+
+			return Response{}, err
+		})
+	}
+}
+
+// TimeoutMiddleware bounds every call of the decorated Server to d, on top of any deadline of the caller context.
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return func(next Server) Server {
+		return ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			return next.Serve(ctx, req)
+		})
+	}
+}
+
+// LoggingMiddleware calls the logger every time the decorated Server returns, like WithLogger.
+// Use SlogLogger to log with a *slog.Logger.
+func LoggingMiddleware(logger func(ctx context.Context, event LogEvent)) Middleware {
+	return func(next Server) Server {
+		return ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+			start := time.Now()
+			res, err := next.Serve(ctx, req)
+			logger(ctx, LogEvent{
+				Request:  req,
+				Duration: time.Since(start),
+				Outcome:  outcome(err),
+				Err:      err,
+				Name:     nameOf(next),
+			})
+
+			return res, err
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordingMiddleware returns a middleware appending its name to the calls before and after calling the next Server.
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next Server) Server {
+		return ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+			*calls = append(*calls, name+" before")
+			res, err := next.Serve(ctx, req)
+			*calls = append(*calls, name+" after")
+			return res, err
+		})
+	}
+}
+
+// Test case for the execution order of chained middleware. The first middleware is the outermost one.
+func TestChain_Order(t *testing.T) {
+	var calls []string
+	srv := Chain(ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+		calls = append(calls, "server")
+		return Response{Data: req.Data}, nil
+	}), recordingMiddleware("a", &calls), recordingMiddleware("b", &calls), recordingMiddleware("c", &calls))
+
+	response, err := srv.Serve(context.Background(), Request{Data: "success"})
+
+	if err != nil || !reflect.DeepEqual(response, Response{Data: "success"}) {
+		t.Errorf("Serve() got %v, %v, wanted %v, %v", response, err, Response{Data: "success"}, nil)
+	}
+	wanted := []string{"a before", "b before", "c before", "server", "c after", "b after", "a after"}
+	if !reflect.DeepEqual(calls, wanted) {
+		t.Errorf("got calls %v, wanted %v", calls, wanted)
+	}
+}
+
+// Test case for chaining the built-in middleware. The server fails once and then times out, until it succeeds.
+func TestChain_BuiltIn(t *testing.T) {
+	workErr := errors.New("error")
+	ts := &TestService{
+		Res:    Response{Data: "success"},
+		Errs:   []error{workErr},
+		Delays: []time.Duration{0, time.Second},
+	}
+	var events []LogEvent
+	srv := Chain(ts,
+		LoggingMiddleware(func(ctx context.Context, event LogEvent) {
+			events = append(events, event)
+		}),
+		RetryMiddleware(3, time.Millisecond),
+		TimeoutMiddleware(20*time.Millisecond),
+	)
+
+	response, err := srv.Serve(context.Background(), Request{Data: "request"})
+
+	if err != nil || !reflect.DeepEqual(response, Response{Data: "success"}) {
+		t.Errorf("Serve() got %v, %v, wanted %v, %v", response, err, Response{Data: "success"}, nil)
+	}
+	if ts.Recorder.Calls != 3 {
+		t.Errorf("got %d calls, wanted %d", ts.Recorder.Calls, 3)
+	}
+	if !ts.Recorder.CtxDeadlineExceeded {
+		t.Errorf("the second call should exceed the timeout")
+	}
+	if len(events) != 1 || events[0].Outcome != OutcomeSuccess {
+		t.Errorf("got events %+v, wanted a single %q event", events, OutcomeSuccess)
+	}
+}
+
+// Test case for the retry middleware giving up when the context gets cancelled while waiting for the next attempt
+func TestRetryMiddleware_Cancelled(t *testing.T) {
+	ts := &TestService{Err: errors.New("error")}
+	srv := Chain(ts, RetryMiddleware(3, time.Second))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if ts.Recorder.Calls != 1 {
+		t.Errorf("got %d calls, wanted %d", ts.Recorder.Calls, 1)
+	}
+}
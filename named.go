@@ -0,0 +1,43 @@
+package service
+
+import "fmt"
+
+// Named is an optional interface of the Servers with a name, e.g. the name of the backend, so that the logs and the
+// errors of the composite Servers (Balancer, LeastConnBalancer, Pipe) and of LoggingMiddleware tell which one served
+// a request. Service and TestService implement it.
+type Named interface {
+	Name() string
+}
+
+// WithName is an option that sets the name of the Service, returned by Name and included in every LogEvent.
+func WithName(name string) Option {
+	return func(s *Service) {
+		s.name = name
+	}
+}
+
+// Name returns the name of the Service, empty without WithName.
+func (s *Service) Name() string {
+	return s.name
+}
+
+// nameOf returns the name of the Server if it implements Named, or an empty string.
+func nameOf(srv Server) string {
+	if n, ok := srv.(Named); ok {
+		return n.Name()
+	}
+
+	return ""
+}
+
+// withServerName wraps the error with the name of the Server, if it has one. It returns nil if err is nil.
+func withServerName(srv Server, err error) error {
+	if err == nil {
+		return nil
+	}
+	if name := nameOf(srv); name != "" {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return err
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Test case for WithName. The logged event carries the configured name, both with WithLogger and with
+// LoggingMiddleware around a named Server.
+func TestService_Serve_Name(t *testing.T) {
+	var events []LogEvent
+	logger := func(ctx context.Context, event LogEvent) {
+		events = append(events, event)
+	}
+
+	srv := NewServiceWithOptions(noopWork, WithName("primary"), WithLogger(logger))
+	if got := srv.Name(); got != "primary" {
+		t.Errorf("Name() got %q, wanted %q", got, "primary")
+	}
+	srv.Serve(context.Background(), Request{})
+	LoggingMiddleware(logger)(&TestService{ServerName: "backend", Synchronous: true}).Serve(context.Background(), Request{})
+	LoggingMiddleware(logger)(ServerFunc(noopWork)).Serve(context.Background(), Request{})
+
+	want := []string{"primary", "backend", ""}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, wanted %d", len(events), len(want))
+	}
+	for i, name := range want {
+		if events[i].Name != name {
+			t.Errorf("event %d got name %q, wanted %q", i, events[i].Name, name)
+		}
+	}
+}
+
+// Test case for the name of the backends in the errors of the balancers. The error is wrapped with the name of the
+// failed backend, and errors.Is still matches it.
+func TestBalancer_Serve_Name(t *testing.T) {
+	workErr := errors.New("error")
+	named := &TestService{ServerName: "backend-a", Err: workErr, Synchronous: true}
+
+	tests := []struct {
+		name string
+		srv  Server
+	}{
+		{name: "balancer", srv: NewBalancer([]Backend{{Server: named, Weight: 1}})},
+		{name: "least conn balancer", srv: NewLeastConnBalancer(named)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.srv.Serve(context.Background(), Request{})
+			if !errors.Is(err, workErr) || !strings.Contains(err.Error(), "backend-a") {
+				t.Errorf("Serve() got err %v, wanted %v wrapped with the name of the backend", err, workErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNilWork is the error returned by New when the work is nil.
+var ErrNilWork = errors.New("service: nil work function")
+
+// ErrInvalidOption is the error wrapped by the errors of New caused by an invalid option, e.g. a negative timeout.
+var ErrInvalidOption = errors.New("service: invalid option")
+
+// Option is a function that configures an optional feature of the Service.
+// Options are passed to NewServiceWithOptions and are applied in the given order.
+type Option func(*Service)
+
+// New is a factory function/constructor for a Service with a context aware work (see NewServiceCtx) and any number
+// of options, like NewServiceWithOptions, which validates the work and the options up front instead of failing
+// later: it returns ErrNilWork if the work is nil, and an error wrapping ErrInvalidOption for every invalid option,
+// e.g. a negative timeout, a concurrency limit lower than 1 or options that are not compatible with each other.
+// Unlike NewServiceWithOptions, which treats such values as disabling the option, New rejects them.
+func New(work func(ctx context.Context, req Request) (Response, error), opts ...Option) (*Service, error) {
+	if work == nil {
+		return nil, ErrNilWork
+	}
+
+	s := NewServiceCtx(work)
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := s.checkOptions(); err != nil {
+		return nil, err
+	}
+	s.build()
+
+	return s, nil
+}
+
+// NewServiceWithOptions is a factory function/constructor for a Service with a context aware work (see NewServiceCtx)
+// and any number of options configuring the optional features of the Service.
+// It panics if the work is nil, or if the options are not compatible with each other, e.g. WithRequireDeadline and
+// WithDefaultTimeout. Use New for getting an error instead.
+func NewServiceWithOptions(work func(ctx context.Context, req Request) (Response, error), opts ...Option) *Service {
+	s := NewServiceCtx(work)
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := s.checkCompatible(); err != nil {
+		panic(err.Error())
+	}
+	s.build()
+
+	return s
+}
+
+// invalidOption records an invalid value of an option, reported by New.
+func (s *Service) invalidOption(format string, args ...any) {
+	s.optionErrs = append(s.optionErrs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidOption}, args...)...))
+}
+
+// checkOptions returns the errors of the invalid options, joined.
+func (s *Service) checkOptions() error {
+	return errors.Join(append(s.optionErrs, s.checkCompatible())...)
+}
+
+// checkCompatible returns an error if the options are not compatible with each other.
+func (s *Service) checkCompatible() error {
+	if s.requireDeadline && s.defaultTimeout > 0 {
+		return fmt.Errorf("%w: WithRequireDeadline and WithDefaultTimeout can't be used together", ErrInvalidOption)
+	}
+
+	return nil
+}
+
+// build creates the parts that depend on more than one option, once all the options are applied.
+func (s *Service) build() {
+	if s.cache != nil {
+		if s.cacheBackend != nil {
+			s.cache.backend = s.cacheBackend
+		}
+		s.cache.strict = s.cacheStrict
+	}
+	s.useKeyFunc()
+	s.useClock()
+	if s.metricsRegisterer != nil {
+		s.metrics = newMetrics(s.metricsRegisterer, s.metricsPrefix, s.cache)
+		if s.cache != nil {
+			s.cache.useMetrics(s.metrics.cache)
+		}
+	}
+	if s.poolSize > 0 {
+		s.pool = newWorkerPool(s.poolSize)
+	}
+	if s.fair && s.sem != nil {
+		s.fairSem = newFairSemaphore(cap(s.sem))
+		s.sem = nil
+	}
+}
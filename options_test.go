@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test case for New. The work and the options are validated up front, and a valid Service serves the requests.
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		work    func(ctx context.Context, req Request) (Response, error)
+		opts    []Option
+		wantErr error
+	}{
+		{name: "valid", work: noopWork, opts: []Option{WithTimeout(time.Second), WithMaxConcurrency(1)}},
+		{name: "zero timeouts", work: noopWork, opts: []Option{WithTimeout(0), WithPerAttemptTimeout(0)}},
+		{name: "nil work", work: nil, wantErr: ErrNilWork},
+		{name: "negative timeout", work: noopWork, opts: []Option{WithTimeout(-time.Second)},
+			wantErr: ErrInvalidOption},
+		{name: "negative per attempt timeout", work: noopWork, opts: []Option{WithPerAttemptTimeout(-time.Second)},
+			wantErr: ErrInvalidOption},
+		{name: "negative default timeout", work: noopWork, opts: []Option{WithDefaultTimeout(-time.Second)},
+			wantErr: ErrInvalidOption},
+		{name: "negative max deadline", work: noopWork, opts: []Option{WithMaxDeadline(-time.Second)},
+			wantErr: ErrInvalidOption},
+		{name: "zero concurrency", work: noopWork, opts: []Option{WithMaxConcurrency(0)}, wantErr: ErrInvalidOption},
+		{name: "negative concurrency", work: noopWork, opts: []Option{WithMaxConcurrency(-1)},
+			wantErr: ErrInvalidOption},
+		{name: "incompatible options", work: noopWork, opts: []Option{WithRequireDeadline(), WithDefaultTimeout(time.Second)},
+			wantErr: ErrInvalidOption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := New(tt.work, tt.opts...)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || srv != nil {
+					t.Errorf("New() got %v and err %v, wanted nil and %v", srv, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() got err %v, wanted %v", err, nil)
+			}
+			if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+				t.Errorf("Serve() got err %v, wanted %v", err, nil)
+			}
+		})
+	}
+}
+
+// Test case for New with many invalid options. Every invalid option is reported.
+func TestNew_ManyInvalidOptions(t *testing.T) {
+	_, err := New(noopWork, WithTimeout(-time.Second), WithMaxConcurrency(0))
+
+	if err == nil || !strings.Contains(err.Error(), "WithTimeout") || !strings.Contains(err.Error(), "WithMaxConcurrency") {
+		t.Errorf("New() got err %v, wanted errors for WithTimeout and WithMaxConcurrency", err)
+	}
+}
+
+// Test case for the constructors with a nil work. They panic right away instead of failing in Serve.
+func TestNewService_NilWork(t *testing.T) {
+	tests := []struct {
+		name string
+		new  func()
+	}{
+		{name: "NewService", new: func() { NewService(nil) }},
+		{name: "NewRequestService", new: func() { NewRequestService(nil) }},
+		{name: "NewServiceCtx", new: func() { NewServiceCtx(nil) }},
+		{name: "NewServiceWithOptions", new: func() { NewServiceWithOptions(nil) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != ErrNilWork.Error() {
+					t.Errorf("%s() panicked with %v, wanted %v", tt.name, r, ErrNilWork.Error())
+				}
+			}()
+			tt.new()
+		})
+	}
+}
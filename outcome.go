@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"errors"
+)
+
+// Outcomes of Serve, used to label metrics and log events.
+const (
+	// OutcomeSuccess is the outcome of a request served without error.
+	OutcomeSuccess = "success"
+	// OutcomeError is the outcome of a request that failed for any reason other than the context.
+	OutcomeError = "error"
+	// OutcomeTimeout is the outcome of a request whose context exceeded its deadline.
+	OutcomeTimeout = "timeout"
+	// OutcomeCancelled is the outcome of a request whose context was cancelled.
+	OutcomeCancelled = "cancelled"
+)
+
+// outcome returns the outcome of a request served with the given error.
+// The timeout and cancelled outcomes are told apart the same way as in TestService.
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, context.DeadlineExceeded):
+		return OutcomeTimeout
+	case errors.Is(err, context.Canceled):
+		return OutcomeCancelled
+	default:
+		return OutcomeError
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrPanic is the error returned by Serve when the work panics. The actual error is a *PanicError.
+var ErrPanic = errors.New("service: work panicked")
+
+// PanicError is the error returned by Serve when the work panics, unless a panic handler is set with
+// WithPanicHandler. errors.Is(err, ErrPanic) reports true for it, and so does errors.Is/As against the recovered
+// value, if the value is an error.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine of the work when it panicked.
+	Stack []byte
+}
+
+// Error returns the message of ErrPanic along with the recovered value.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrPanic, e.Value)
+}
+
+// Unwrap returns ErrPanic, along with the recovered value if it is an error.
+func (e *PanicError) Unwrap() []error {
+	if err, ok := e.Value.(error); ok {
+		return []error{ErrPanic, err}
+	}
+
+	return []error{ErrPanic}
+}
+
+// WithPanicHandler is an option that customizes the conversion of a panic of the work to an error.
+// Panics of the work are always recovered, since they happen in a goroutine launched by Serve and would otherwise
+// crash the whole process. By default they are converted to a *PanicError. The handler receives the recovered
+// value and the stack trace of the goroutine of the work, and returns the error to use instead.
+func WithPanicHandler(handler func(recovered any, stack []byte) error) Option {
+	return func(s *Service) {
+		s.panicHandler = handler
+	}
+}
+
+// PanicMode is the behavior of the Service when the work panics. See WithPanicMode.
+type PanicMode int
+
+const (
+	// PanicRecover converts the panics of the work to errors (see WithPanicHandler). It is the default.
+	PanicRecover PanicMode = iota
+	// PanicRepanic re-raises the panics of the work in the goroutine that called Serve.
+	PanicRepanic
+)
+
+// WithPanicMode is an option that sets the behavior of the Service when the work panics.
+// With PanicRecover, the default, the panic is converted to an error, so that the process survives it.
+// With PanicRepanic the panic fails loudly instead, e.g. in development: it is recovered in the goroutine of the
+// work, which can't be left to crash the process before releasing its resources, and re-raised in Serve with the
+// recovered value, after the resources of the call are released and the call is logged and measured. The panic
+// skips the retries, the fallback and the negative cache, and the panic handler is not called. The stack trace is
+// the one of Serve, not the one of the work goroutine, which is lost.
+// The panic can't be re-raised if Serve has already returned, e.g. because the context was done, since there is no
+// caller left to panic in: such panics are still recovered and handed to the late result handler as *PanicError
+// (see WithLateResultHandler). Every caller sharing the outcome of the panicked work panics, e.g. with
+// WithSingleFlight.
+func WithPanicMode(mode PanicMode) Option {
+	return func(s *Service) {
+		s.panicMode = mode
+	}
+}
+
+// repanicked returns the *PanicError of err if the panic must be re-raised in Serve (see WithPanicMode), or nil.
+func (s *Service) repanicked(err error) *PanicError {
+	if s.panicMode != PanicRepanic || err == nil {
+		return nil
+	}
+	var pe *PanicError
+	if errors.As(err, &pe) {
+		return pe
+	}
+
+	return nil
+}
+
+// recoverWork recovers a panic of the work and converts it to an error, stored in err.
+// It must be deferred directly by the function that calls the work.
+func (s *Service) recoverWork(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	stack := debug.Stack()
+	if s.panicHandler != nil && s.panicMode != PanicRepanic {
+		*err = s.panicHandler(r, stack)
+		return
+	}
+	*err = &PanicError{Value: r, Stack: stack}
+}
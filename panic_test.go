@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Test case for a panicking work. Serve returns a *PanicError instead of crashing.
+func TestService_Serve_Panic(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		panic("boom")
+	})
+
+	response, err := srv.Serve(context.Background(), Request{})
+
+	if !errors.Is(err, ErrPanic) {
+		t.Errorf("Serve() got err %v, wanted %v", err, ErrPanic)
+	}
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Serve() got err %T, wanted %T", err, panicErr)
+	}
+	if panicErr.Value != "boom" {
+		t.Errorf("got recovered value %v, wanted %v", panicErr.Value, "boom")
+	}
+	if len(panicErr.Stack) == 0 {
+		t.Errorf("got empty stack trace")
+	}
+	if !reflect.DeepEqual(response, Response{}) {
+		t.Errorf("Serve() got response %v, wanted %v", response, Response{})
+	}
+}
+
+// Test case for a work panicking with an error. The error can be inspected with errors.Is.
+func TestService_Serve_PanicWithError(t *testing.T) {
+	panicErr := errors.New("boom")
+	srv := NewService(func() (Response, error) {
+		panic(panicErr)
+	})
+
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if !errors.Is(err, ErrPanic) || !errors.Is(err, panicErr) {
+		t.Errorf("Serve() got err %v, wanted both %v and %v", err, ErrPanic, panicErr)
+	}
+}
+
+// Test case for a custom panic handler. Its error is returned by Serve.
+func TestService_Serve_PanicHandler(t *testing.T) {
+	wantErr := errors.New("custom")
+	var gotRecovered any
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		panic("boom")
+	}, WithPanicHandler(func(recovered any, stack []byte) error {
+		gotRecovered = recovered
+		return wantErr
+	}))
+
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if err != wantErr {
+		t.Errorf("Serve() got err %v, wanted %v", err, wantErr)
+	}
+	if gotRecovered != "boom" {
+		t.Errorf("handler got recovered value %v, wanted %v", gotRecovered, "boom")
+	}
+}
+
+// Test case for the panic modes. PanicRecover returns a *PanicError, while PanicRepanic re-raises the recovered
+// value in Serve, without retrying the work or calling the fallback.
+func TestService_Serve_PanicMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        PanicMode
+		wantPanic   bool
+		wantErr     error
+		wantAttempt int
+	}{
+		{name: "recover", mode: PanicRecover, wantErr: ErrPanic, wantAttempt: 3},
+		{name: "repanic", mode: PanicRepanic, wantPanic: true, wantAttempt: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallbackCalled := false
+			srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+				panic("boom")
+			},
+				WithPanicMode(tt.mode),
+				WithRetry(3, 0),
+				WithFallback(func(ctx context.Context, req Request, cause error) (Response, error) {
+					fallbackCalled = true
+					return Response{}, cause
+				}))
+
+			var recovered any
+			var err error
+			func() {
+				defer func() {
+					recovered = recover()
+				}()
+				_, err = srv.Serve(context.Background(), Request{})
+			}()
+
+			if tt.wantPanic {
+				if recovered != "boom" {
+					t.Errorf("Serve() panicked with %v, wanted %v", recovered, "boom")
+				}
+				if fallbackCalled {
+					t.Errorf("fallback called, wanted no call")
+				}
+			} else {
+				if recovered != nil {
+					t.Errorf("Serve() panicked with %v, wanted no panic", recovered)
+				}
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Serve() got err %v, wanted %v", err, tt.wantErr)
+				}
+			}
+			if got := srv.Attempts(); got != tt.wantAttempt {
+				t.Errorf("got %d attempts, wanted %d", got, tt.wantAttempt)
+			}
+			// The resources of the call are released even when the panic is re-raised.
+			if err := srv.Close(context.Background()); err != nil {
+				t.Errorf("Close() got err %v, wanted %v", err, nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// WithPartialResults is an option that lets the work report partial results with ReportPartial, e.g. the first
+// page of a search, so that Serve returns the last reported partial result with a nil error, instead of the context
+// error, when the context is done before the work returns. ServeDetailed flags such a response as partial. Partial
+// results are never cached (see WithCache). Without partial results Serve returns the context error as usual.
+func WithPartialResults() Option {
+	return func(s *Service) {
+		s.partialResults = true
+	}
+}
+
+// ReportPartial records res as the latest partial result of the work whose context is ctx, replacing any partial
+// result reported before, and reports whether it was recorded. It records nothing, returning false, unless the
+// Service uses WithPartialResults. It is safe for concurrent use and never blocks.
+func ReportPartial(ctx context.Context, res Response) bool {
+	p, ok := ctx.Value(partialKey{}).(*partial)
+	if !ok {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.res = res
+	p.ok = true
+
+	return true
+}
+
+// partialKey is the context key of the partial result of the work.
+type partialKey struct{}
+
+// partial holds the latest partial result reported by the work.
+type partial struct {
+	// mu guards the fields below.
+	mu  sync.Mutex
+	res Response
+	// ok is true once a partial result is reported.
+	ok bool
+}
+
+// get returns the latest partial result, and whether there is one.
+func (p *partial) get() (Response, bool) {
+	if p == nil {
+		return Response{}, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.res, p.ok
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// partialWork reports a partial result and then blocks until its context is done.
+func partialWork(ctx context.Context, req Request) (Response, error) {
+	ReportPartial(ctx, Response{Data: "first"})
+	ReportPartial(ctx, Response{Data: "second"})
+	<-ctx.Done()
+	return Response{}, ctx.Err()
+}
+
+// Test case for a work reporting partial results and then blocking. The last partial result is returned on timeout.
+func TestService_ServeDetailed_PartialResults(t *testing.T) {
+	srv := NewServiceWithOptions(partialWork, WithPartialResults(), WithCache(time.Minute, nil))
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		result := srv.ServeDetailed(ctx, Request{})
+		cancel()
+
+		want := ServeResult{Response: Response{Data: "second"}, Partial: true}
+		if result != want {
+			t.Errorf("ServeDetailed() got %+v, wanted %+v", result, want)
+		}
+	}
+	// The partial results are not cached.
+	if srv.Attempts() != 2 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+	}
+}
+
+// Test case for partial results without WithPartialResults. They are not recorded and the context error is returned.
+func TestService_Serve_PartialResultsDisabled(t *testing.T) {
+	srv := NewServiceWithOptions(partialWork)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+}
+
+// Test case for a work returning in time after reporting a partial result. The final response is returned.
+func TestService_ServeDetailed_PartialResultsInTime(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		ReportPartial(ctx, Response{Data: "partial"})
+		return Response{Data: "final"}, nil
+	}, WithPartialResults())
+
+	result := srv.ServeDetailed(context.Background(), Request{})
+
+	want := ServeResult{Response: Response{Data: "final"}}
+	if result != want {
+		t.Errorf("ServeDetailed() got %+v, wanted %+v", result, want)
+	}
+}
+
+func TestReportPartial(t *testing.T) {
+	if ReportPartial(context.Background(), Response{}) {
+		t.Errorf("ReportPartial() should report false without WithPartialResults")
+	}
+}
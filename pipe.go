@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// Pipe composes the servers sequentially, returning a Server that sends the request to the first server and the
+// response of every server to the next one, returning the response of the last server. The response of a stage is
+// turned to the request of the next stage by copying its Data and Body. Use PipeWith for a custom mapping.
+func Pipe(servers ...Server) Server {
+	adapters := make([]func(Response) Request, 0, len(servers))
+	for i := 1; i < len(servers); i++ {
+		adapters = append(adapters, forward)
+	}
+
+	return PipeWith(adapters, servers...)
+}
+
+// PipeWith composes the servers sequentially like Pipe, using the nth adapter to turn the response of the nth server
+// to the request of the next one, so there must be exactly one adapter less than the servers, or PipeWith panics.
+// All the stages share the context of the call. The error of any stage aborts the rest of the pipeline and it is
+// returned wrapped with the index of the stage, and its name if it has one (see Named), so that errors.Is and
+// errors.As can inspect it.
+// Calling the returned Server without servers returns ErrNoServers.
+func PipeWith(adapters []func(Response) Request, servers ...Server) Server {
+	if len(servers) > 0 && len(adapters) != len(servers)-1 {
+		panic(fmt.Sprintf("service: %d adapters for %d servers, wanted %d", len(adapters), len(servers), len(servers)-1))
+	}
+
+	return ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+		if len(servers) == 0 {
+			return Response{}, ErrNoServers
+		}
+
+		var res Response
+		for i, srv := range servers {
+			if i > 0 {
+				req = adapters[i-1](res)
+			}
+			var err error
+			res, err = srv.Serve(ctx, req)
+			if err != nil {
+				return Response{}, fmt.Errorf("service: pipe stage %d: %w", i, withServerName(srv, err))
+			}
+		}
+
+		return res, nil
+	})
+}
+
+// forward is the adapter of Pipe, turning a response to a request with the same Data and Body.
+func forward(res Response) Request {
+	return Request{Data: res.Data, Body: res.Body}
+}
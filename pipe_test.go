@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Test case for two services composed with an adapter in between. The adapted response of the first one is the
+// request of the second one.
+func TestPipeWith(t *testing.T) {
+	first := &TestService{Res: Response{Data: "first"}}
+	second := &TestService{Res: Response{Data: "second"}}
+	adapter := func(res Response) Request {
+		return Request{Data: "adapted " + res.Data}
+	}
+
+	res, err := PipeWith([]func(Response) Request{adapter}, first, second).Serve(context.Background(), Request{Data: "req"})
+
+	if err != nil {
+		t.Errorf("Serve() got err %v, wanted %v", err, nil)
+	}
+	if want := (Response{Data: "second"}); !reflect.DeepEqual(res, want) {
+		t.Errorf("Serve() got response %v, wanted %v", res, want)
+	}
+	if got, want := first.Snapshot().Request, (Request{Data: "req"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("first stage got request %v, wanted %v", got, want)
+	}
+	if got, want := second.Snapshot().Request, (Request{Data: "adapted first"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("second stage got request %v, wanted %v", got, want)
+	}
+}
+
+// Test case for a failing stage. The rest of the pipeline is not called.
+func TestPipe_StageError(t *testing.T) {
+	wantErr := errors.New("error")
+	first := &TestService{Err: wantErr}
+	second := &TestService{}
+
+	_, err := Pipe(first, second).Serve(context.Background(), Request{})
+
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Serve() got err %v, wanted %v", err, wantErr)
+	}
+	if calls := second.Snapshot().Calls; calls != 0 {
+		t.Errorf("second stage got %d calls, wanted %d", calls, 0)
+	}
+}
+
+// Test case for the default adapter of Pipe, forwarding the Data of the response
+func TestPipe(t *testing.T) {
+	first := &TestService{Res: Response{Data: "first"}}
+	second := &TestService{}
+
+	if _, err := Pipe(first, second).Serve(context.Background(), Request{}); err != nil {
+		t.Errorf("Serve() got err %v, wanted %v", err, nil)
+	}
+	if got, want := second.Snapshot().Request, (Request{Data: "first"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("second stage got request %v, wanted %v", got, want)
+	}
+}
+
+// Test case for a pipeline without servers
+func TestPipe_NoServers(t *testing.T) {
+	if _, err := Pipe().Serve(context.Background(), Request{}); err != ErrNoServers {
+		t.Errorf("Serve() got err %v, wanted %v", err, ErrNoServers)
+	}
+}
+
+// Test case for a wrong number of adapters. PipeWith panics.
+func TestPipeWith_WrongAdapters(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("PipeWith() should panic")
+		}
+	}()
+
+	PipeWith(nil, &TestService{}, &TestService{})
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// WithWorkerPool is an option that runs the work of every request on a fixed pool of size worker goroutines,
+// started when the Service is created, instead of launching a new goroutine for every call of Serve.
+// The pool bounds the number of goroutines running the work and saves the cost of starting them at very high rates,
+// but requests queue up when every worker is busy: Serve waits for a free worker, or until the context gets
+// cancelled, in which case the context error is returned wrapped. Like with WithMaxConcurrency, a worker is busy
+// until the work returns, even if Serve has already returned. Hedged copies of the work (see WithHedging) still run
+// on their own goroutines. The workers are stopped by Close, once every request has finished.
+// A size lower or equal to 0 disables the pool.
+func WithWorkerPool(size int) Option {
+	return func(s *Service) {
+		s.poolSize = size
+	}
+}
+
+// PoolStats returns the number of workers of the pool running work, and the number of requests waiting for a free
+// worker. They are always 0 without WithWorkerPool.
+func (s *Service) PoolStats() (active, queued int) {
+	if s.pool == nil {
+		return 0, 0
+	}
+
+	return int(atomic.LoadInt64(&s.pool.active)), int(atomic.LoadInt64(&s.pool.queued))
+}
+
+// workerPool is a fixed pool of goroutines running jobs.
+type workerPool struct {
+	// active is the number of workers running a job. It is accessed atomically.
+	active int64
+	// queued is the number of jobs waiting for a free worker. It is accessed atomically.
+	queued int64
+
+	// jobs is unbuffered, so a job is handed over only to a free worker.
+	jobs chan func()
+	// stop closes jobs once.
+	stop sync.Once
+}
+
+// newWorkerPool creates a pool and starts its workers.
+func newWorkerPool(size int) *workerPool {
+	p := &workerPool{jobs: make(chan func())}
+	for i := 0; i < size; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+// work runs the jobs until the pool is stopped.
+func (p *workerPool) work() {
+	for job := range p.jobs {
+		atomic.AddInt64(&p.active, 1)
+		job()
+		atomic.AddInt64(&p.active, -1)
+	}
+}
+
+// submit hands the job over to a free worker, blocking until there is one or the context gets cancelled, in which
+// case the context error is returned.
+func (p *workerPool) submit(ctx context.Context, job func()) error {
+	atomic.AddInt64(&p.queued, 1)
+	defer atomic.AddInt64(&p.queued, -1)
+
+	select {
+	case p.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		return contextErr(ctx)
+	}
+}
+
+// close stops the workers. No job must be submitted after that.
+func (p *workerPool) close() {
+	p.stop.Do(func() {
+		close(p.jobs)
+	})
+}
+
+// launch runs the job on the worker pool, if there is one, or on a new goroutine.
+func (s *Service) launch(ctx context.Context, job func()) error {
+	if s.pool == nil {
+		go job()
+		return nil
+	}
+
+	if err := s.pool.submit(ctx, job); err != nil {
+		return fmt.Errorf("service: waiting for a free worker: %w", err)
+	}
+
+	return nil
+}
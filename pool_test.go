@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// Test case for the worker pool. The third concurrent call waits for one of the two workers.
+func TestService_Serve_WorkerPool(t *testing.T) {
+	release := make(chan struct{})
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		<-release
+		return Response{Data: req.Data}, nil
+	}, WithWorkerPool(2))
+
+	futures := []*Future{
+		srv.ServeAsync(context.Background(), Request{Data: "first"}),
+		srv.ServeAsync(context.Background(), Request{Data: "second"}),
+		srv.ServeAsync(context.Background(), Request{Data: "third"}),
+	}
+	waitFor(t, func() bool {
+		active, queued := srv.PoolStats()
+		return active == 2 && queued == 1
+	})
+
+	close(release)
+	for _, f := range futures {
+		if response, err := f.Wait(); err != nil || response.Data == "" {
+			t.Errorf("Wait() got %v, %v, wanted a response", response, err)
+		}
+	}
+	waitFor(t, func() bool {
+		active, queued := srv.PoolStats()
+		return active == 0 && queued == 0
+	})
+}
+
+// Test case for a call cancelled while waiting for a free worker
+func TestService_Serve_WorkerPoolCancelled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	ts := &TestService{}
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		if req.Data == "block" {
+			<-release
+		}
+		return ts.Serve(ctx, req)
+	}, WithWorkerPool(1))
+
+	srv.ServeAsync(context.Background(), Request{Data: "block"})
+	waitFor(t, func() bool {
+		active, _ := srv.PoolStats()
+		return active == 1
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if _, queued := srv.PoolStats(); queued != 0 {
+		t.Errorf("got %d queued calls, wanted %d", queued, 0)
+	}
+}
+
+// Test case for Close stopping the workers of the pool
+func TestService_Close_WorkerPool(t *testing.T) {
+	before := runtime.NumGoroutine()
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: req.Data}, nil
+	}, WithWorkerPool(10))
+	if after := runtime.NumGoroutine(); after < before+10 {
+		t.Errorf("got %d goroutines after the creation, wanted at least %d", after, before+10)
+	}
+
+	response, err := srv.Serve(context.Background(), Request{Data: "success"})
+	if err != nil || !reflect.DeepEqual(response, Response{Data: "success"}) {
+		t.Errorf("Serve() got %v, %v, wanted %v, %v", response, err, Response{Data: "success"}, nil)
+	}
+	if err := srv.Close(context.Background()); err != nil {
+		t.Fatalf("Close() got err %v, wanted %v", err, nil)
+	}
+
+	waitFor(t, func() bool { return runtime.NumGoroutine() <= before })
+}
+
+// Benchmark of Serve launching a goroutine per call
+func BenchmarkServe_Goroutines(b *testing.B) {
+	srv := NewServiceWithOptions(noopWork)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			srv.Serve(context.Background(), Request{})
+		}
+	})
+}
+
+// Benchmark of Serve running the work on a worker pool
+func BenchmarkServe_WorkerPool(b *testing.B) {
+	srv := NewServiceWithOptions(noopWork, WithWorkerPool(runtime.GOMAXPROCS(0)))
+	defer srv.Close(context.Background())
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			srv.Serve(context.Background(), Request{})
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoQuorum is the error wrapped by the errors of Quorum and QuorumWith when fewer servers than the quorum succeed.
+var ErrNoQuorum = errors.New("service: no quorum")
+
+// Quorum sends the request to all the servers at the same time, e.g. to the replicas of a replicated read, and
+// returns the first successful response as soon as quorum servers have succeeded, cancelling the context of the rest
+// of the calls. A quorum lower than 1 is treated as 1.
+// If the quorum can't be reached, either because too many servers failed or because the context got done first, the
+// returned error wraps ErrNoQuorum along with the errors of the failed servers and the context error, if any.
+func Quorum(ctx context.Context, req Request, quorum int, servers ...Server) (Response, error) {
+	return QuorumWith(ctx, req, quorum, nil, servers...)
+}
+
+// QuorumWith is like Quorum, but the quorum is reached only when quorum successful responses agree with each other,
+// according to equal. A nil equal considers every successful response in agreement, like Quorum does.
+// The bodies of the collected responses that are not returned are released, since nobody is going to read them.
+func QuorumWith(ctx context.Context, req Request, quorum int, equal func(a, b Response) bool,
+	servers ...Server) (Response, error) {
+	if len(servers) == 0 {
+		return Response{}, ErrNoServers
+	}
+	if quorum < 1 {
+		quorum = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Use buffered channel to avoid goroutine leak, since the rest of the results are not received after the quorum
+	results := resultChan(len(servers))
+	for _, srv := range servers {
+		go func(srv Server, req Request) {
+			res, err := srv.Serve(ctx, req)
+			results <- result{res: res, err: withServerName(srv, err)}
+		}(srv, withOwnMeta(req))
+	}
+
+	// votes are the successful responses, grouped by agreement.
+	var votes [][]Response
+	var errs []error
+collect:
+	for pending := len(servers); pending > 0 && largestVote(votes)+pending >= quorum; pending-- {
+		select {
+		case r := <-results:
+			if r.err != nil {
+				errs = append(errs, r.err)
+				continue
+			}
+			i := vote(votes, r.res, equal)
+			if i == len(votes) {
+				votes = append(votes, nil)
+			}
+			votes[i] = append(votes[i], r.res)
+			if len(votes[i]) == quorum {
+				releaseVotes(votes, &votes[i][0])
+				return votes[i][0], nil
+			}
+		case <-ctx.Done():
+			errs = append(errs, contextErr(ctx))
+			break collect
+		}
+	}
+	releaseVotes(votes, nil)
+
+	err := fmt.Errorf("%w: %d of %d in agreement", ErrNoQuorum, largestVote(votes), quorum)
+	if len(errs) > 0 {
+		err = fmt.Errorf("%w: %w", err, errors.Join(errs...))
+	}
+
+	return Response{}, err
+}
+
+// vote returns the index of the group of votes agreeing with the response, or len(votes) if there is none.
+func vote(votes [][]Response, res Response, equal func(a, b Response) bool) int {
+	for i, group := range votes {
+		if equal == nil || equal(group[0], res) {
+			return i
+		}
+	}
+
+	return len(votes)
+}
+
+// largestVote returns the size of the largest group of votes.
+func largestVote(votes [][]Response) int {
+	largest := 0
+	for _, group := range votes {
+		largest = max(largest, len(group))
+	}
+
+	return largest
+}
+
+// releaseVotes releases the bodies of the votes, except the body of the kept response, if any.
+func releaseVotes(votes [][]Response, kept *Response) {
+	for _, group := range votes {
+		for i := range group {
+			if &group[i] != kept {
+				DrainAndClose(group[i].Body)
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// blockingServer returns a server that blocks until its context is done, reporting the context error.
+func blockingServer(cancelled chan<- error) Server {
+	return ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return Response{}, ctx.Err()
+	})
+}
+
+// Test case for Quorum returning once 3 of 5 servers succeed and cancelling the rest of them
+func TestQuorum(t *testing.T) {
+	cancelled := make(chan error, 2)
+	servers := []Server{
+		&TestService{Res: Response{Data: "replica"}},
+		blockingServer(cancelled),
+		&TestService{Res: Response{Data: "replica"}, DelayReponse: 10 * time.Millisecond},
+		blockingServer(cancelled),
+		&TestService{Res: Response{Data: "replica"}, DelayReponse: 20 * time.Millisecond},
+	}
+
+	res, err := Quorum(context.Background(), Request{}, 3, servers...)
+
+	if err != nil || !reflect.DeepEqual(res, Response{Data: "replica"}) {
+		t.Errorf("Quorum() got %v, %v, wanted %v, %v", res, err, Response{Data: "replica"}, nil)
+	}
+	for range 2 {
+		select {
+		case err := <-cancelled:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("blocking server got err %v, wanted %v", err, context.Canceled)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("the blocking servers were not cancelled")
+		}
+	}
+}
+
+// Test case for Quorum failing as soon as too many servers have failed, with the errors of all of them
+func TestQuorum_TooManyFailures(t *testing.T) {
+	first, second, third := errors.New("first"), errors.New("second"), errors.New("third")
+	servers := []Server{
+		&TestService{Err: first},
+		&TestService{Res: Response{Data: "replica"}},
+		&TestService{Err: second},
+		&TestService{Err: third},
+		blockingServer(make(chan error, 1)),
+	}
+
+	_, err := Quorum(context.Background(), Request{}, 3, servers...)
+
+	for _, wanted := range []error{ErrNoQuorum, first, second, third} {
+		if !errors.Is(err, wanted) {
+			t.Errorf("Quorum() got err %v, wanted %v", err, wanted)
+		}
+	}
+}
+
+// Test case for Quorum failing with the context error when the quorum is not reached before the deadline
+func TestQuorum_Deadline(t *testing.T) {
+	cancelled := make(chan error, 3)
+	servers := []Server{
+		&TestService{Res: Response{Data: "replica"}},
+		&TestService{Res: Response{Data: "replica"}},
+		blockingServer(cancelled),
+		blockingServer(cancelled),
+		blockingServer(cancelled),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := Quorum(ctx, Request{}, 3, servers...)
+
+	if !errors.Is(err, ErrNoQuorum) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Quorum() got err %v, wanted %v and %v", err, ErrNoQuorum, context.DeadlineExceeded)
+	}
+}
+
+// Test case for QuorumWith reaching the quorum only with responses in agreement
+func TestQuorumWith_Comparator(t *testing.T) {
+	servers := []Server{
+		&TestService{Res: Response{Data: "a"}},
+		&TestService{Res: Response{Data: "b"}},
+		&TestService{Res: Response{Data: "b"}, DelayReponse: 10 * time.Millisecond},
+		&TestService{Res: Response{Data: "a"}, DelayReponse: 10 * time.Millisecond},
+		&TestService{Res: Response{Data: "a"}, DelayReponse: 20 * time.Millisecond},
+	}
+	equal := func(a, b Response) bool { return a.Data == b.Data }
+
+	res, err := QuorumWith(context.Background(), Request{}, 3, equal, servers...)
+
+	if err != nil || !reflect.DeepEqual(res, Response{Data: "a"}) {
+		t.Errorf("QuorumWith() got %v, %v, wanted %v, %v", res, err, Response{Data: "a"}, nil)
+	}
+
+	_, err = QuorumWith(context.Background(), Request{}, 4, equal, servers...)
+
+	if !errors.Is(err, ErrNoQuorum) {
+		t.Errorf("QuorumWith() got err %v, wanted %v", err, ErrNoQuorum)
+	}
+}
+
+// Test case for Quorum without servers
+func TestQuorum_NoServers(t *testing.T) {
+	_, err := Quorum(context.Background(), Request{}, 1)
+
+	if !errors.Is(err, ErrNoServers) {
+		t.Errorf("Quorum() got err %v, wanted %v", err, ErrNoServers)
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is the error returned by Serve when the rate limit is reached and the RateLimitReject mode is used.
+var ErrRateLimited = errors.New("service: rate limit exceeded")
+
+// RateLimitMode defines what Serve does when the rate limit is reached.
+type RateLimitMode int
+
+const (
+	// RateLimitBlock makes Serve wait until the request is allowed, or until the context gets cancelled.
+	// This is the default mode.
+	RateLimitBlock RateLimitMode = iota
+	// RateLimitReject makes Serve fail fast with ErrRateLimited.
+	RateLimitReject
+)
+
+// WithRateLimit is an option that limits the requests served per second, using a token bucket that holds up to
+// burst tokens and is refilled with rps tokens per second. Every request consumes a token before the work is
+// launched, so retries of the same request don't consume extra tokens.
+// The limiter is shared by all the goroutines calling Serve on the same Service.
+// What happens when there is no token available depends on the mode (see WithRateLimitMode).
+// A rps lower or equal to 0 disables the limit.
+func WithRateLimit(rps int, burst int) Option {
+	return func(s *Service) {
+		if rps <= 0 {
+			s.limiter = nil
+			return
+		}
+		s.limiter = newTokenBucket(float64(rps), burst)
+	}
+}
+
+// WithRateLimitMode is an option that sets what Serve does when the rate limit of WithRateLimit is reached.
+func WithRateLimitMode(mode RateLimitMode) Option {
+	return func(s *Service) {
+		s.limiterMode = mode
+	}
+}
+
+// limit consumes a token of the rate limiter, if there is one, according to the rate limit mode.
+func (s *Service) limit(ctx context.Context) error {
+	if s.limiter == nil {
+		return nil
+	}
+	if s.limiterMode == RateLimitReject {
+		if !s.limiter.allow() {
+			return ErrRateLimited
+		}
+		return nil
+	}
+
+	if err := s.limiter.wait(ctx); err != nil {
+		return fmt.Errorf("service: waiting for the rate limiter: %w", err)
+	}
+
+	return nil
+}
+
+// tokenBucket is a token bucket rate limiter, safe for concurrent use.
+type tokenBucket struct {
+	// rate is the number of tokens added per second.
+	rate float64
+	// burst is the maximum number of tokens.
+	burst float64
+	// clock is the source of time. See setClock.
+	clock Clock
+
+	// mu guards the fields below.
+	mu sync.Mutex
+	// tokens is the number of tokens available at the last refill.
+	tokens float64
+	// last is the last time the tokens were refilled.
+	last time.Time
+}
+
+// newTokenBucket creates a full token bucket. A burst lower than 1 is treated as 1.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		clock:  realClock{},
+	}
+}
+
+// allow consumes a token if there is one available, and reports if it did.
+func (b *tokenBucket) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+
+	return true
+}
+
+// setClock replaces the source of time, restarting the refill from the current time of the clock.
+func (b *tokenBucket) setClock(clock Clock) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.clock = clock
+	b.last = clock.Now()
+}
+
+// available returns the number of tokens available.
+func (b *tokenBucket) available() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+
+	return b.tokens
+}
+
+// wait blocks until a token is consumed or the context gets cancelled, in which case the context error is returned.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		b.refill()
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		// The time needed for the missing fraction of a token to be added.
+		d := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		// Another goroutine may take the token in the meantime, in which case we wait again.
+		if err := sleep(ctx, b.clock, d); err != nil {
+			return err
+		}
+	}
+}
+
+// refill adds the tokens accumulated since the last refill. It must be called with mu held.
+func (b *tokenBucket) refill() {
+	now := b.clock.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test case for the reject mode. Requests above the burst fail fast with ErrRateLimited.
+func TestService_Serve_RateLimitReject(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: "success"}, nil
+	}, WithRateLimit(1, 2), WithRateLimitMode(RateLimitReject))
+
+	for i := 0; i < 2; i++ {
+		if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+			t.Errorf("Serve() should not return an error, got %v", err)
+		}
+	}
+
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Serve() got err %v, wanted %v", err, ErrRateLimited)
+	}
+	if srv.Attempts() != 2 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+	}
+}
+
+// Test case for the block mode. Requests above the burst wait for a token.
+func TestService_Serve_RateLimitBlock(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: "success"}, nil
+	}, WithRateLimit(20, 1))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+			t.Errorf("Serve() should not return an error, got %v", err)
+		}
+	}
+
+	// The first request uses the burst token, the next two wait 50ms each.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Serve() served 3 requests in %v, wanted at least %v", elapsed, 100*time.Millisecond)
+	}
+}
+
+// Test case for the block mode with a cancelled context. Serve stops waiting and returns the context error.
+func TestService_Serve_RateLimitBlockCancelled(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: "success"}, nil
+	}, WithRateLimit(1, 1))
+
+	srv.Serve(context.Background(), Request{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if srv.Attempts() != 1 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 1)
+	}
+}
+
+// Test case for concurrent callers. The limiter is shared, so only the burst is served immediately.
+func TestService_Serve_RateLimitShared(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: "success"}, nil
+	}, WithRateLimit(1, 5), WithRateLimitMode(RateLimitReject))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	rejected := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := srv.Serve(context.Background(), Request{}); errors.Is(err, ErrRateLimited) {
+				mu.Lock()
+				rejected++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if rejected != 15 {
+		t.Errorf("got %d rejected requests, wanted %d", rejected, 15)
+	}
+}
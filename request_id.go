@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+// WithRequestID is an option that makes sure that every call of Serve has a request ID in its context under key,
+// for correlating the logs of the work and of the Service. An ID already present under key is preserved, otherwise
+// genFn generates one and it is added to the context passed to the work and to the logger. The ID is included in
+// the LogEvent too. A nil genFn uses NewRequestID. The key must be comparable and, like any context key, it should
+// be of an unexported type to avoid collisions.
+func WithRequestID(genFn func() string, key any) Option {
+	return func(s *Service) {
+		if genFn == nil {
+			genFn = NewRequestID
+		}
+		s.requestIDGen = genFn
+		s.requestIDKey = key
+	}
+}
+
+// RequestIDFromContext returns the request ID stored in the context under key, and whether there is one.
+func RequestIDFromContext(ctx context.Context, key any) (string, bool) {
+	id, ok := ctx.Value(key).(string)
+	if !ok || id == "" {
+		return "", false
+	}
+
+	return id, true
+}
+
+// NewRequestID returns a random ID in the format of a version 4 UUID, e.g. "1b4e28ba-2fa1-4d3b-a3f5-ef19b5a7633b".
+func NewRequestID() string {
+	var b [16]byte
+	// Read never returns an error, it crashes the program instead.
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// ensureRequestID returns the context with a request ID and the ID, generating one if the context has none.
+// It returns the context unchanged and an empty ID without WithRequestID.
+func (s *Service) ensureRequestID(ctx context.Context) (context.Context, string) {
+	if s.requestIDGen == nil {
+		return ctx, ""
+	}
+	if id, ok := RequestIDFromContext(ctx, s.requestIDKey); ok {
+		return ctx, id
+	}
+	id := s.requestIDGen()
+
+	return context.WithValue(ctx, s.requestIDKey, id), id
+}
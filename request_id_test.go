@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+// requestIDKey is the context key of the request ID in the tests.
+type requestIDKey struct{}
+
+// Test case for the request ID, generated when missing and preserved when present, in the context of the work and
+// in the log event
+func TestService_Serve_RequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "generate", ctx: context.Background(), want: "generated"},
+		{name: "preserve", ctx: context.WithValue(context.Background(), requestIDKey{}, "present"), want: "present"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := &TestService{RecordKeys: []any{requestIDKey{}}}
+			var event LogEvent
+			srv := NewServiceWithOptions(ts.Serve,
+				WithRequestID(func() string { return "generated" }, requestIDKey{}),
+				WithLogger(func(ctx context.Context, e LogEvent) { event = e }),
+			)
+
+			if _, err := srv.Serve(tt.ctx, Request{}); err != nil {
+				t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+			}
+
+			if got := ts.Snapshot().CtxValues[requestIDKey{}]; got != tt.want {
+				t.Errorf("work got request ID %v, wanted %v", got, tt.want)
+			}
+			if event.RequestID != tt.want {
+				t.Errorf("LogEvent got request ID %v, wanted %v", event.RequestID, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	if _, ok := RequestIDFromContext(context.Background(), requestIDKey{}); ok {
+		t.Errorf("RequestIDFromContext() should report no ID for an empty context")
+	}
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "id")
+	if id, ok := RequestIDFromContext(ctx, requestIDKey{}); !ok || id != "id" {
+		t.Errorf("RequestIDFromContext() got (%v, %v), wanted (%v, %v)", id, ok, "id", true)
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	id := NewRequestID()
+	if !uuid.MatchString(id) {
+		t.Errorf("NewRequestID() got %v, wanted a version 4 UUID", id)
+	}
+	if NewRequestID() == id {
+		t.Errorf("NewRequestID() returned the same ID twice")
+	}
+}
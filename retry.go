@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// WithRetry is an option that retries the work when it returns an error.
+// attempts is the maximum number of times the work will be called, including the first call, so values lower than 2
+// disable the retries. backoff is the time to wait between two attempts.
+// The wait is interrupted as soon as the context gets cancelled, in which case the context error is returned.
+// A wait that would last beyond the deadline of the context is skipped and the work is retried immediately for the
+// last time, so that the remaining time is spent on an attempt instead of sleeping until the deadline.
+// If all the attempts fail, the error of the last attempt is returned unchanged.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(s *Service) {
+		s.retryAttempts = attempts
+		s.retryBackoff = backoff
+	}
+}
+
+// WithRetryIf is an option that retries the work only when the returned error satisfies the predicate.
+// When the predicate returns false the error is considered permanent (e.g. a validation failure) and it is
+// returned immediately, without consuming the rest of the attempts. When the predicate returns true the work is
+// retried as long as there are attempts left, so the maximum number of attempts set with WithRetry still applies.
+// It has no effect without WithRetry. Use WithRetryIf(IsRetryable) to retry only errors marked with RetryableError.
+func WithRetryIf(predicate func(error) bool) Option {
+	return func(s *Service) {
+		s.retryIf = predicate
+	}
+}
+
+// WithOnAttempt is an option that calls fn after every attempt of the work (see WithRetry) with the number of the
+// attempt, starting from 1, and its error, nil on success, e.g. for tracing how a flaky downstream behaved during a
+// request. Unlike the logger, which is called once per request, it is called once per attempt. It is called
+// synchronously between the attempts, so it should be cheap. An attempt still running when Serve returns, e.g.
+// because the context is done, is reported when it returns, after Serve. An attempt rejected by the circuit breaker
+// is reported with ErrCircuitOpen.
+func WithOnAttempt(fn func(attempt int, err error)) Option {
+	return func(s *Service) {
+		s.onAttempt = fn
+	}
+}
+
+// retryableError is the error returned by RetryableError.
+type retryableError struct {
+	err error
+}
+
+// Error returns the message of the wrapped error.
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error, so that errors.Is and errors.As can inspect it.
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// RetryableError wraps the error returned by the work in order to mark it as retryable (see IsRetryable).
+// It returns nil if err is nil.
+func RetryableError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &retryableError{err: err}
+}
+
+// IsRetryable reports whether any error in the chain of err has been marked with RetryableError.
+func IsRetryable(err error) bool {
+	var target *retryableError
+	return errors.As(err, &target)
+}
+
+// Attempts returns the number of times the work has been called by the Service, counting every attempt of every
+// served request, including the hedged copies (see WithHedging).
+func (s *Service) Attempts() int {
+	return int(atomic.LoadInt64(&s.attempts))
+}
+
+// do calls the work, retrying it according to the retry options, and returns the outcome of the last attempt.
+func (s *Service) do(ctx context.Context, req Request) (Response, error) {
+	attempts := s.retryAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			// Don't sleep through the deadline of the context: spend the remaining time on a last attempt instead,
+			// or give up returning the error of the last attempt if the deadline has already passed.
+			// The remaining time is measured with the clock of the Service, like the wait.
+			wait := s.waitBefore(attempt - 1)
+			if deadline, ok := ctx.Deadline(); ok {
+				if remaining := deadline.Sub(s.clock.Now()); wait >= remaining {
+					if remaining <= 0 {
+						return Response{}, err
+					}
+					wait = 0
+					attempts = attempt + 1
+				}
+			}
+			// Give up if the retry budget is exhausted, returning the error of the last attempt.
+			if s.retryBudget != nil && !s.retryBudget.withdraw() {
+				return Response{}, err
+			}
+			// Wait before the next attempt, unless the context gets cancelled in the meantime.
+			if ctxErr := sleep(ctx, s.clock, wait); ctxErr != nil {
+				return Response{}, fmt.Errorf("service: retry aborted after %d attempts: %w", attempt, ctxErr)
+			}
+		}
+
+		var resp Response
+		resp, err = s.attempt(ctx, req, attempts-attempt)
+		if s.onAttempt != nil {
+			s.onAttempt(attempt+1, err)
+		}
+		if errors.Is(err, ErrCircuitOpen) {
+			return Response{}, err
+		}
+		if err == nil {
+			if s.retryBudget != nil {
+				s.retryBudget.deposit()
+			}
+			return resp, nil
+		}
+		if (s.retryIf != nil && !s.retryIf(err)) || s.repanicked(err) != nil {
+			return Response{}, err
+		}
+	}
+
+	return Response{}, err
+}
+
+// attempt calls the work once, guarded by the circuit breaker if there is one. attemptsLeft is the number of
+// attempts left, including this one.
+func (s *Service) attempt(ctx context.Context, req Request, attemptsLeft int) (Response, error) {
+	// Bound the attempt with the per attempt timeout, if there is one.
+	if timeout := s.attemptTimeoutOf(ctx, attemptsLeft); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if s.breaker == nil {
+		return s.hedge(ctx, req)
+	}
+
+	trial, err := s.breaker.allow()
+	if err != nil {
+		return Response{}, err
+	}
+	resp, err := s.hedge(ctx, req)
+	s.breaker.record(trial, err)
+
+	return resp, err
+}
+
+// call calls the work, counting the call, converting a panic of the work to an error and rejecting the invalid
+// responses.
+func (s *Service) call(ctx context.Context, req Request) (res Response, err error) {
+	atomic.AddInt64(&s.attempts, 1)
+	defer s.recoverWork(&err)
+
+	res, err = s.work(ctx, s.injectBudget(ctx, req))
+	if err == nil {
+		if err = s.checkResult(res); err != nil {
+			return Response{}, err
+		}
+	}
+
+	return res, err
+}
+
+// waitBefore returns the time to wait before the given retry, where retry 0 is the wait before the second attempt.
+func (s *Service) waitBefore(retry int) time.Duration {
+	if s.backoff != nil {
+		return s.backoff(retry)
+	}
+
+	return s.retryBackoff
+}
+
+// sleep waits for the given duration or until the context gets cancelled, whatever happens first.
+// It returns the context error in case of cancellation, or nil otherwise.
+func sleep(ctx context.Context, clock Clock, d time.Duration) error {
+	if d <= 0 {
+		return contextErr(ctx)
+	}
+
+	// Use a timer instead of After so that it can be stopped and released on cancellation.
+	timer := clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return contextErr(ctx)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import "sync"
+
+// maxRetryBudgetTokens is the maximum number of tokens earned by successful requests, so that a long period of
+// success doesn't allow a storm of retries later on.
+const maxRetryBudgetTokens = 100
+
+// WithRetryBudget is an option that limits the retries of all the requests served by the Service, so that a storm
+// of failures doesn't multiply the load of the downstream service with retries.
+// Every retry consumes a token of the budget. Every successful request adds ratio tokens to the budget (e.g. 0.1
+// allows a retry for every 10 successful requests), up to 100 tokens, and minPerSec tokens are added every second
+// regardless, so that a few retries are always allowed even when nothing succeeds.
+// When the budget is exhausted the request is not retried, and the error of the last attempt is returned
+// immediately. It has no effect without WithRetry.
+func WithRetryBudget(ratio float64, minPerSec int) Option {
+	return func(s *Service) {
+		s.retryBudget = newRetryBudget(ratio, minPerSec)
+	}
+}
+
+// RetryBudgetTokens returns the number of retries currently allowed by the retry budget (see WithRetryBudget).
+// It returns 0 when there is no retry budget.
+func (s *Service) RetryBudgetTokens() float64 {
+	if s.retryBudget == nil {
+		return 0
+	}
+
+	return s.retryBudget.available()
+}
+
+// retryBudget is the budget of the retries, safe for concurrent use.
+type retryBudget struct {
+	// ratio is the number of tokens added by every successful request.
+	ratio float64
+	// reserve holds the tokens added every second, regardless of the successful requests. Nil if there are none.
+	reserve *tokenBucket
+
+	// mu guards tokens.
+	mu sync.Mutex
+	// tokens is the number of tokens added by the successful requests.
+	tokens float64
+}
+
+// newRetryBudget creates a retry budget.
+func newRetryBudget(ratio float64, minPerSec int) *retryBudget {
+	b := &retryBudget{ratio: ratio}
+	if minPerSec > 0 {
+		b.reserve = newTokenBucket(float64(minPerSec), minPerSec)
+	}
+
+	return b
+}
+
+// deposit adds the tokens of a successful request.
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += b.ratio
+	if b.tokens > maxRetryBudgetTokens {
+		b.tokens = maxRetryBudgetTokens
+	}
+}
+
+// withdraw consumes a token for a retry, preferring the tokens of the reserve. It returns false if there is none.
+func (b *retryBudget) withdraw() bool {
+	if b.reserve != nil && b.reserve.allow() {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+
+	return true
+}
+
+// available returns the number of tokens of the budget.
+func (b *retryBudget) available() float64 {
+	b.mu.Lock()
+	tokens := b.tokens
+	b.mu.Unlock()
+
+	if b.reserve != nil {
+		tokens += b.reserve.available()
+	}
+
+	return tokens
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// Test case for the retries being skipped when the retry budget is exhausted
+func TestService_Serve_RetryBudgetExhausted(t *testing.T) {
+	workErr := errors.New("error")
+	ts := &TestService{Err: workErr}
+	srv := NewServiceWithOptions(ts.Serve, WithRetry(3, 0), WithRetryBudget(0.5, 0))
+
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if !errors.Is(err, workErr) {
+		t.Errorf("Serve() got err %v, wanted %v", err, workErr)
+	}
+	if ts.Recorder.Calls != 1 {
+		t.Errorf("got %d calls, wanted %d", ts.Recorder.Calls, 1)
+	}
+}
+
+// Test case for the successful requests adding tokens to the retry budget
+func TestService_Serve_RetryBudgetDeposit(t *testing.T) {
+	workErr := errors.New("error")
+	ts := &TestService{Errs: []error{nil, nil, workErr, workErr, workErr}}
+	srv := NewServiceWithOptions(ts.Serve, WithRetry(3, 0), WithRetryBudget(0.5, 0))
+
+	srv.Serve(context.Background(), Request{})
+	srv.Serve(context.Background(), Request{})
+	if tokens := srv.RetryBudgetTokens(); tokens != 1 {
+		t.Errorf("RetryBudgetTokens() got %v, wanted %v", tokens, 1)
+	}
+
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if !errors.Is(err, workErr) {
+		t.Errorf("Serve() got err %v, wanted %v", err, workErr)
+	}
+	// Two successful calls, plus the failing call and a single retry.
+	if ts.Recorder.Calls != 4 {
+		t.Errorf("got %d calls, wanted %d", ts.Recorder.Calls, 4)
+	}
+	if tokens := srv.RetryBudgetTokens(); tokens != 0 {
+		t.Errorf("RetryBudgetTokens() got %v, wanted %v", tokens, 0)
+	}
+}
+
+// Test case for the minimum retries per second allowed regardless of the successful requests
+func TestService_Serve_RetryBudgetMinPerSec(t *testing.T) {
+	workErr := errors.New("error")
+	ts := &TestService{Err: workErr}
+	srv := NewServiceWithOptions(ts.Serve, WithRetry(5, 0), WithRetryBudget(0, 2))
+
+	srv.Serve(context.Background(), Request{})
+
+	// The first call plus two retries of the reserve.
+	if ts.Recorder.Calls != 3 {
+		t.Errorf("got %d calls, wanted %d", ts.Recorder.Calls, 3)
+	}
+}
+
+// Test case for the tokens of a Service without retry budget
+func TestService_RetryBudgetTokens_Disabled(t *testing.T) {
+	srv := NewService(func() (Response, error) {
+		return Response{}, nil
+	})
+
+	if tokens := srv.RetryBudgetTokens(); tokens != 0 {
+		t.Errorf("RetryBudgetTokens() got %v, wanted %v", tokens, 0)
+	}
+}
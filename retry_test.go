@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Test case for a work that fails once and then succeeds. The second attempt serves the request.
+func TestService_Serve_RetrySuccess(t *testing.T) {
+	calls := 0
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		calls++
+		if calls == 1 {
+			return Response{}, errors.New("error")
+		}
+		return Response{Data: "success"}, nil
+	}, WithRetry(3, 10*time.Millisecond))
+
+	response, err := srv.Serve(context.Background(), Request{})
+
+	if err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+
+	wantResp := Response{Data: "success"}
+	if !reflect.DeepEqual(response, wantResp) {
+		t.Errorf("Serve() got response %v, wanted %v", response, wantResp)
+	}
+	if srv.Attempts() != 2 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+	}
+}
+
+// Test case for a work that always fails. The error of the last attempt is returned unchanged.
+func TestService_Serve_RetryExhausted(t *testing.T) {
+	wantErr := errors.New("error")
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, wantErr
+	}, WithRetry(3, 10*time.Millisecond))
+
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if err != wantErr {
+		t.Errorf("Serve() got err %v, wanted %v", err, wantErr)
+	}
+	if srv.Attempts() != 3 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 3)
+	}
+}
+
+// Test case for a cancellation during the backoff. The retry loop stops and the context error is returned.
+func TestService_Serve_RetryCancelled(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, errors.New("error")
+	}, WithRetry(3, 2000*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > 1000*time.Millisecond {
+		t.Errorf("Serve() returned after %v, wanted the backoff to be interrupted", elapsed)
+	}
+	if srv.Attempts() != 1 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 1)
+	}
+}
+
+// Test case for a permanent error. The predicate rejects the error, so the work is called only once.
+func TestService_Serve_RetryIfPermanentError(t *testing.T) {
+	wantErr := errors.New("permanent")
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, wantErr
+	}, WithRetry(3, 10*time.Millisecond), WithRetryIf(IsRetryable))
+
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if err != wantErr {
+		t.Errorf("Serve() got err %v, wanted %v", err, wantErr)
+	}
+	if srv.Attempts() != 1 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 1)
+	}
+}
+
+// Test case for a retryable error. The work is retried until the attempts are exhausted.
+func TestService_Serve_RetryIfRetryableError(t *testing.T) {
+	wantErr := errors.New("temporary")
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, RetryableError(wantErr)
+	}, WithRetry(3, 10*time.Millisecond), WithRetryIf(IsRetryable))
+
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Serve() got err %v, wanted %v", err, wantErr)
+	}
+	if !IsRetryable(err) {
+		t.Errorf("IsRetryable() should be true for %v", err)
+	}
+	if srv.Attempts() != 3 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 3)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	err := errors.New("error")
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "plain error", err: err, want: false},
+		{name: "retryable error", err: RetryableError(err), want: true},
+		{name: "wrapped retryable error", err: fmt.Errorf("wrapped: %w", RetryableError(err)), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() got %v, wanted %v", got, tt.want)
+			}
+		})
+	}
+	if RetryableError(nil) != nil {
+		t.Errorf("RetryableError(nil) should be nil")
+	}
+}
+
+// Test case for a backoff longer than the remaining time of the context. The work is retried immediately for the
+// last time, instead of sleeping until the deadline. The clock is never advanced, so any sleep would last until the
+// deadline.
+func TestService_Serve_RetryBackoffBeyondDeadline(t *testing.T) {
+	workErr := errors.New("error")
+	tests := []struct {
+		name     string
+		errs     []error
+		wantErr  error
+		attempts int
+	}{
+		{name: "last attempt succeeds", errs: []error{workErr}, wantErr: nil, attempts: 2},
+		{name: "last attempt fails", errs: []error{workErr, workErr, workErr}, wantErr: workErr, attempts: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := &TestService{Errs: tt.errs}
+			srv := NewServiceWithOptions(ts.Serve, WithRetry(5, time.Hour), WithClock(NewFakeClock(time.Now())))
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			start := time.Now()
+			_, err := srv.Serve(ctx, Request{})
+
+			if err != tt.wantErr {
+				t.Errorf("Serve() got err %v, wanted %v", err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("Serve() returned after %v, wanted the backoff to be skipped", elapsed)
+			}
+			if srv.Attempts() != tt.attempts {
+				t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), tt.attempts)
+			}
+		})
+	}
+}
+
+// Test case for a clock past the deadline of the context. The remaining time is measured with the clock of the
+// Service, so the work is not retried even though the deadline has not passed in wall-clock time.
+func TestService_Serve_RetryDeadlineOnClock(t *testing.T) {
+	workErr := errors.New("error")
+	ts := &TestService{Err: workErr}
+	clock := NewFakeClock(time.Now().Add(time.Hour))
+	srv := NewServiceWithOptions(ts.Serve, WithRetry(5, time.Minute), WithClock(clock))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := srv.Serve(ctx, Request{})
+
+	if err != workErr {
+		t.Errorf("Serve() got err %v, wanted %v", err, workErr)
+	}
+	if srv.Attempts() != 1 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 1)
+	}
+}
+
+// Test case for the attempt callback. It is called after every attempt with its error, before Serve returns.
+func TestService_Serve_OnAttempt(t *testing.T) {
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+	ts := &TestService{Errs: []error{errFirst, errSecond, nil}, Synchronous: true}
+	var attempts []int
+	var errs []error
+	srv := NewServiceWithOptions(ts.Serve, WithRetry(3, 0), WithOnAttempt(func(attempt int, err error) {
+		attempts = append(attempts, attempt)
+		errs = append(errs, err)
+	}))
+
+	if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+		t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+	}
+
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(attempts, want) {
+		t.Errorf("callback got attempts %v, wanted %v", attempts, want)
+	}
+	if want := []error{errFirst, errSecond, nil}; !reflect.DeepEqual(errs, want) {
+		t.Errorf("callback got errors %v, wanted %v", errs, want)
+	}
+}
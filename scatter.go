@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNoServers is the error returned by Any, Quorum and QuorumWith when called without servers.
+var ErrNoServers = errors.New("service: no servers")
+
+// Any sends the request to all the servers at the same time and returns the first successful response, cancelling
+// the context of the rest of the calls. If every server fails, the error of the last one to fail is returned.
+// If the context gets cancelled before any server succeeds, the context error is returned.
+// The responses of the calls returning after the first success, or after the cancellation of the context, are
+// received in the background and their bodies are drained and closed, since nobody is going to read them.
+func Any(ctx context.Context, req Request, servers ...Server) (Response, error) {
+	if len(servers) == 0 {
+		return Response{}, ErrNoServers
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Use buffered channel to avoid goroutine leak, since only the first success is received
+	results := resultChan(len(servers))
+	for _, srv := range servers {
+		go func(srv Server, req Request) {
+			res, err := srv.Serve(ctx, req)
+			results <- result{res: res, err: err}
+		}(srv, withOwnMeta(req))
+	}
+
+	var err error
+	for pending := len(servers); pending > 0; pending-- {
+		select {
+		case r := <-results:
+			if r.err == nil {
+				discardResults(results, pending-1)
+				return r.res, nil
+			}
+			err = r.err
+		case <-ctx.Done():
+			discardResults(results, pending)
+			return Response{}, contextErr(ctx)
+		}
+	}
+
+	return Response{}, err
+}
+
+// discardResults receives in the background the given number of results still pending on the channel, and releases
+// the bodies of their responses, since nobody is going to read them.
+func discardResults(results <-chan result, pending int) {
+	if pending == 0 {
+		return
+	}
+
+	go func() {
+		for ; pending > 0; pending-- {
+			r := <-results
+			DrainAndClose(r.res.Body)
+		}
+	}()
+}
+
+// All sends the request to all the servers at the same time and waits for all of them to return.
+// The nth response and error are the outcome of the nth server.
+// Every server is expected to return when the context gets cancelled.
+func All(ctx context.Context, req Request, servers ...Server) ([]Response, []error) {
+	responses := make([]Response, len(servers))
+	errs := make([]error, len(servers))
+
+	var wg sync.WaitGroup
+	for i, srv := range servers {
+		wg.Add(1)
+		go func(i int, srv Server, req Request) {
+			defer wg.Done()
+			responses[i], errs[i] = srv.Serve(ctx, req)
+		}(i, srv, withOwnMeta(req))
+	}
+	wg.Wait()
+
+	return responses, errs
+}
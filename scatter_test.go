@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Test case for Any returning the first successful response and cancelling the rest of the servers
+func TestAny_FirstSuccess(t *testing.T) {
+	failing := &TestService{Err: errors.New("error")}
+	cancelled := make(chan error, 1)
+	slow := ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return Response{}, ctx.Err()
+	})
+	fast := &TestService{Res: Response{Data: "fast"}, DelayReponse: 10 * time.Millisecond}
+
+	response, err := Any(context.Background(), Request{Data: "request"}, failing, slow, fast)
+
+	if err != nil || !reflect.DeepEqual(response, Response{Data: "fast"}) {
+		t.Errorf("Any() got %v, %v, wanted %v, %v", response, err, Response{Data: "fast"}, nil)
+	}
+	select {
+	case err := <-cancelled:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("slow server got err %v, wanted %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("the slow server was not cancelled")
+	}
+}
+
+// Test case for Any returning the error of the last server to fail when every server fails
+func TestAny_AllFailed(t *testing.T) {
+	first := &TestService{Err: errors.New("first")}
+	last := &TestService{Err: errors.New("last"), DelayReponse: 20 * time.Millisecond}
+
+	_, err := Any(context.Background(), Request{}, first, last)
+
+	if !errors.Is(err, last.Err) {
+		t.Errorf("Any() got err %v, wanted %v", err, last.Err)
+	}
+}
+
+// Test case for Any without servers
+func TestAny_NoServers(t *testing.T) {
+	_, err := Any(context.Background(), Request{})
+
+	if !errors.Is(err, ErrNoServers) {
+		t.Errorf("Any() got err %v, wanted %v", err, ErrNoServers)
+	}
+}
+
+// Test case for Any returning the context error when the context gets cancelled
+func TestAny_Cancelled(t *testing.T) {
+	slow := &TestService{DelayReponse: time.Second}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := Any(ctx, Request{}, slow)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Any() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+}
+
+// Test case for the losers of Any, succeeding after the winner or after the cancellation of the context. The bodies
+// of their responses are closed, while the body of the winner is left to the caller.
+func TestAny_LosersClosed(t *testing.T) {
+	released := make(chan struct{})
+	close(released)
+	// servers returns servers succeeding after the delays, with the bodies of their responses.
+	servers := func(delays ...time.Duration) ([]Server, []*slowBody) {
+		servers := make([]Server, len(delays))
+		bodies := make([]*slowBody, len(delays))
+		for i, delay := range delays {
+			bodies[i] = &slowBody{release: released}
+			servers[i] = ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+				time.Sleep(delay)
+				return Response{Body: bodies[i]}, nil
+			})
+		}
+		return servers, bodies
+	}
+
+	won, wonBodies := servers(0, 20*time.Millisecond, 40*time.Millisecond)
+	res, err := Any(context.Background(), Request{}, won...)
+	if err != nil || res.Body != wonBodies[0] {
+		t.Fatalf("Any() got (%v, %v), wanted the response of the first server", res, err)
+	}
+	cancelled, cancelledBodies := servers(20*time.Millisecond, 40*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := Any(ctx, Request{}, cancelled...); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Any() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+
+	waitFor(t, func() bool {
+		return wonBodies[1].closed.Load() && wonBodies[2].closed.Load() && cancelledBodies[0].closed.Load() &&
+			cancelledBodies[1].closed.Load()
+	})
+	if wonBodies[0].closed.Load() {
+		t.Errorf("the body of the winner was closed, wanted it left to the caller")
+	}
+}
+
+// Test case for All returning the outcome of every server, aligned by index
+func TestAll(t *testing.T) {
+	workErr := errors.New("error")
+	servers := []Server{
+		&TestService{Res: Response{Data: "first"}, DelayReponse: 20 * time.Millisecond},
+		&TestService{Err: workErr},
+		&TestService{Res: Response{Data: "third"}},
+	}
+
+	responses, errs := All(context.Background(), Request{}, servers...)
+
+	wantedResponses := []Response{{Data: "first"}, {}, {Data: "third"}}
+	wantedErrs := []error{nil, workErr, nil}
+	if !reflect.DeepEqual(responses, wantedResponses) {
+		t.Errorf("All() got responses %v, wanted %v", responses, wantedResponses)
+	}
+	if !reflect.DeepEqual(errs, wantedErrs) {
+		t.Errorf("All() got errs %v, wanted %v", errs, wantedErrs)
+	}
+}
+
+// Test case for All with servers writing the Meta of the request. Each one writes its own copy. Run it with the race
+// detector.
+func TestAll_RequestMeta(t *testing.T) {
+	server := ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+		req.Meta["written"] = true
+		return Response{}, nil
+	})
+	meta := map[string]any{}
+
+	All(context.Background(), Request{Meta: meta}, server, server, server)
+
+	if len(meta) != 0 {
+		t.Errorf("the caller got meta %v, wanted it empty", meta)
+	}
+}
@@ -2,64 +2,581 @@ package service
 
 import (
 	"context"
+	"io"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Request is the request that the service will serve.
+//
+// The optional Body is owned by the work until the work returns. If Serve returns before that, because the context
+// got cancelled, the caller must not touch the Body anymore: it is drained and closed (see DrainAndClose) as soon
+// as the abandoned work returns, so that it is never left half-consumed. A Body can be read only once, so it
+// doesn't play well with the options serving a request more than once (WithRetry and WithHedging).
 type Request struct {
 	// Sample field for the sake of the example. Could be one or more fields of any type.
 	Data string
+	// Body is an optional stream of bytes, e.g. the body of an HTTP request.
+	Body io.Reader
+	// Budget is the time left until the deadline of the context when the work is called, or 0 if there is no
+	// deadline. It is set by the Service only when WithBudgetInjection is used.
+	Budget time.Duration
+	// Meta is scratch space for the hooks of the Service and the middleware handling the same request, e.g. a value
+	// derived by the validator and read by the logger. It is nil until the first write (see SetMeta), except that
+	// Serve creates it before calling the validator of WithValidator, so that the layers after the validator share
+	// its writes. The map must not be accessed concurrently, so every goroutine serving the request concurrently with
+	// the layers of the Service (the work, the hedged copies, the shadow, the servers of Any, All and Quorum) gets
+	// its own copy, and its writes are not seen by the rest of the layers. It is not part of the cache key, unless
+	// the key function of WithCache uses it.
+	Meta map[string]any
+	// Priority is the priority of the request waiting for a slot of WithMaxConcurrency along with WithFairness:
+	// the requests with a higher priority get the freed slots first. The default priority is 0, and negative
+	// priorities are lower than the default.
+	Priority int
 }
 
 // Response is the actual reponse of the service in absence of error (happy path)
+//
+// The optional Body is owned by the caller of Serve, who must close it after reading it, if it is an io.Closer.
+// The Body of the response of abandoned work is drained and closed by the Service, since nobody is going to read it.
+// A Body can be read only once, so it doesn't play well with the options sharing a response (WithCache and
+// WithSingleFlight).
 type Response struct {
 	// Sample field for the sake of the example. Could be one or more fields of any type.
 	Data string
+	// Body is an optional stream of bytes, e.g. the body of an HTTP response.
+	Body io.Reader
+	// Compressed is true if the Body is gzipped. See WithResponseCompression.
+	Compressed bool
 }
 
-// Service is a struct representing the actual service. For the sake of the example it has only one field
-// which simulates the work that needs to be completed.
+// Service is a struct representing the actual service. For the sake of the example it has only one mandatory field
+// which simulates the work that needs to be completed. The rest of the fields configure optional features
+// and are set using options (see NewServiceWithOptions).
 type Service struct {
+	// attempts counts the calls of the work. It is accessed atomically, so it is kept at the beginning of the struct
+	// in order to be 64-bit aligned on 32-bit platforms.
+	attempts int64
+	// hedges counts the hedged copies of the work. It is accessed atomically, see attempts.
+	hedges int64
+
 	// func representing the actual work that needs to be done in order to calculate the response.
 	// Could be an external HTTP call, db interaction, data processing or whatever else.
-	work func() (Response, error)
+	// Every constructor adapts the supplied work to this shape, so Serve has a single way to call it.
+	// The context and the request passed to Serve are handed to the work as they are, so that the work
+	// can propagate the deadline and the cancellation to any downstream call.
+	work func(ctx context.Context, req Request) (Response, error)
+
+	// validate rejects the malformed requests. See WithValidator.
+	validate func(req Request) error
+	// retryAttempts is the maximum number of calls of the work per request. See WithRetry.
+	retryAttempts int
+	// retryBackoff is the time to wait between two attempts. See WithRetry.
+	retryBackoff time.Duration
+	// backoff calculates the time to wait before the given retry. If set, it is used instead of retryBackoff.
+	// See WithExponentialBackoff.
+	backoff func(retry int) time.Duration
+	// onAttempt is called after every attempt of the work. See WithOnAttempt.
+	onAttempt func(attempt int, err error)
+	// retryIf decides if an error of the work should be retried. If nil, every error is retried. See WithRetryIf.
+	retryIf func(error) bool
+	// retryBudget limits the retries of all the requests. See WithRetryBudget.
+	retryBudget *retryBudget
+	// breaker stops calling a failing work. See WithCircuitBreaker.
+	breaker *CircuitBreaker
+	// limiter limits the requests served per second. See WithRateLimit.
+	limiter *tokenBucket
+	// keyedLimiter limits the requests served per second for every key. See WithKeyedRateLimit.
+	keyedLimiter *keyedLimiter
+	// limiterMode defines what happens when the rate limit is reached. See WithRateLimitMode.
+	limiterMode RateLimitMode
+	// sem is a semaphore limiting the concurrent work executions. See WithMaxConcurrency.
+	sem chan struct{}
+	// fair makes the concurrency limit grant the slots in arrival order. See WithFairness.
+	fair bool
+	// fairSem replaces sem when fair is set. It is created after all the options are applied.
+	fairSem *fairSemaphore
+	// hedgeDelay is the time to wait before launching a hedged copy of the work. See WithHedging.
+	hedgeDelay time.Duration
+	// maxHedges is the maximum number of hedged copies of the work per attempt. See WithHedging.
+	maxHedges int
+	// compressMin is the size above which the bodies are compressed, 0 without compression. See
+	// WithResponseCompression.
+	compressMin int
+	// resultPool is the pool of the channels receiving the outcome of the work, if not the default one.
+	resultPool *resultPool
+	// validateResult checks the responses of the work. See WithResultValidator.
+	validateResult func(res Response) error
+	// responseEqual tells if two responses are equal. See WithResponseComparator.
+	responseEqual func(a, b Response) bool
+	// onMismatch is called when two executions of a request get different responses. See WithMismatchHandler.
+	onMismatch func(req Request, a, b Response)
+	// optionErrs are the errors of the invalid options, reported by New.
+	optionErrs []error
+	// keyFn is the key of the requests of the options without their own key function. See WithKeyFunc.
+	keyFn func(Request) string
+	// panicMode re-raises the panics of the work in Serve. See WithPanicMode.
+	panicMode PanicMode
+	// fallback replaces the error of Serve with a degraded response. See WithFallback.
+	fallback func(ctx context.Context, req Request, cause error) (Response, error)
+	// transform post-processes the responses of the work. See WithResponseTransform.
+	transform func(ctx context.Context, req Request, res Response) (Response, error)
+	// cache caches the successful responses. See WithCache.
+	cache *responseCache
+	// cacheBackend replaces the MemoryCache of the cache, and cacheStrict makes its errors fail the request.
+	// See WithCacheBackend and WithCacheStrictness.
+	cacheBackend Cache
+	cacheStrict  bool
+	// negativeTTL is the time the errors satisfying negativeShouldCache are cached. See WithNegativeCache.
+	negativeTTL         time.Duration
+	negativeShouldCache func(error) bool
+	// flights deduplicates concurrent identical requests. See WithSingleFlight.
+	flights *flightGroup
+	// metricsRegisterer registers the metrics. See WithMetrics.
+	metricsRegisterer prometheus.Registerer
+	// metricsPrefix is the prefix of the metric names. See WithMetricsPrefix.
+	metricsPrefix string
+	// metrics records the metrics of Serve. It is created after all the options are applied.
+	metrics *metrics
+	// tracer creates a span for every call of Serve. See WithTracer.
+	tracer trace.Tracer
+	// classifier classifies the errors of Serve for the metrics and the logs. See WithErrorClassifier.
+	classifier ErrorClassifier
+	// logger is called when Serve returns. See WithLogger.
+	logger func(ctx context.Context, event LogEvent)
+	// logSampler picks the successful requests that are logged. See WithLogSampling.
+	logSampler *sampler
+	// panicHandler converts a panic of the work to an error. See WithPanicHandler.
+	panicHandler func(recovered any, stack []byte) error
+	// batchConcurrency limits the requests of a batch served at the same time. See WithBatchConcurrency.
+	batchConcurrency int
+	// minBudget is the minimum remaining time of the context for launching the work. See WithMinBudget.
+	minBudget time.Duration
+	// workContext cancels the context of the work when Serve stops waiting for it. See WithWorkContext.
+	workContext bool
+	// ctxValues are added to the context of the work. See WithContextValues.
+	ctxValues map[any]any
+	// ctxOverride makes ctxValues take precedence over the values of the caller. See WithOverride.
+	ctxOverride bool
+	// timeout bounds every call of Serve. See WithTimeout.
+	timeout time.Duration
+	// maxDeadline caps the deadline of every call of Serve. See WithMaxDeadline.
+	maxDeadline time.Duration
+	// requestTimeout returns the timeout of a request. See WithTimeoutFromRequest.
+	requestTimeout func(Request) time.Duration
+	// attemptTimeout bounds every attempt of the work. See WithPerAttemptTimeout.
+	attemptTimeout time.Duration
+	// budgetDecay splits the remaining time among the attempts left. See WithBudgetDecay.
+	budgetDecay bool
+	// requireDeadline rejects the contexts without deadline. See WithRequireDeadline.
+	requireDeadline bool
+	// defaultTimeout bounds the calls of Serve whose context has no deadline. See WithDefaultTimeout.
+	defaultTimeout time.Duration
+	// lateResultHandler is called with the outcome of the abandoned work. See WithLateResultHandler.
+	lateResultHandler func(res Response, err error, lateBy time.Duration)
+	// budgetInjection sets Request.Budget before calling the work. See WithBudgetInjection.
+	budgetInjection bool
+	// shedder rejects a fraction of the requests when the work is slow. See WithLoadShedding.
+	shedder *loadShedder
+	// requestIDGen generates the missing request IDs, stored in the context under requestIDKey. See WithRequestID.
+	requestIDGen func() string
+	requestIDKey any
+	// onGoroutineStart and onGoroutineEnd are called by the goroutine of the work. See WithGoroutineHooks.
+	onGoroutineStart func()
+	onGoroutineEnd   func()
+	// shadow receives a copy of the requests picked by shadowSampler. See WithShadow and WithShadowCompare.
+	shadow        Server
+	shadowSampler *sampler
+	shadowCompare func(primary, shadow Response, primaryErr, shadowErr error)
+	// coalescer groups the requests in batches, replacing the work. See WithCoalescing.
+	coalescer *coalescer
+	// partialResults lets the work report partial results. See WithPartialResults.
+	partialResults bool
+	// name is the name of the Service. See WithName.
+	name string
+	// healthProbe replaces the work in Healthy. See WithHealthProbe.
+	healthProbe func(ctx context.Context) error
+
+	// poolSize is the number of workers of the pool. See WithWorkerPool.
+	poolSize int
+	// pool runs the work instead of a new goroutine per call. It is created after all the options are applied.
+	pool *workerPool
+	// clock is the source of time of the Service. See WithClock.
+	clock Clock
+
+	// closeMu guards closed.
+	closeMu sync.RWMutex
+	// closed is true after Close has been called. See Close.
+	closed bool
+	// inflight tracks the requests being served and the work still running, so that Close can wait for them.
+	inflight sync.WaitGroup
 }
 
-// NewService is a factory function/constructor for the Service
+// NewService is a factory function/constructor for the Service.
+// The work has no access to the context, so it cannot stop when the context gets cancelled.
+// Use NewServiceCtx for work that needs to honor the cancellation. It panics if the work is nil.
 func NewService(work func() (Response, error)) *Service {
+	if work == nil {
+		panic(ErrNilWork.Error())
+	}
+
+	return NewServiceCtx(func(context.Context, Request) (Response, error) {
+		return work()
+	})
+}
+
+// NewRequestService is a factory function/constructor for a Service whose work receives the request passed to
+// Serve. This way a single long-lived Service can serve many different requests. It panics if the work is nil.
+func NewRequestService(work func(req Request) (Response, error)) *Service {
+	if work == nil {
+		panic(ErrNilWork.Error())
+	}
+
+	return NewServiceCtx(func(_ context.Context, req Request) (Response, error) {
+		return work(req)
+	})
+}
+
+// NewServiceCtx is a factory function/constructor for a Service whose work receives the context and the request
+// passed to Serve. The work should pass the context to any downstream call (HTTP, db etc) in order to stop as soon
+// as the context gets cancelled. It panics if the work is nil, instead of failing later in Serve.
+func NewServiceCtx(work func(ctx context.Context, req Request) (Response, error)) *Service {
+	if work == nil {
+		panic(ErrNilWork.Error())
+	}
+
 	return &Service{
-		work: work,
+		work:  work,
+		clock: realClock{},
 	}
 }
 
 // Serve is the method of the Service that handles the request.
 // It responds back with a Response on the happy  path or an error in case of failure
+//
+// When the request is not served because the context got cancelled or exceeded its deadline, the returned error is
+// either the context error itself or an error wrapping it, depending on where the request was stopped (e.g. while
+// waiting for the rate limiter). Either way errors.Is(err, context.DeadlineExceeded) and
+// errors.Is(err, context.Canceled) can be used to tell the two cases apart, whatever options are used.
+// If the context was cancelled with a cause (see context.WithCancelCause), the error carries the cause as well, so
+// errors.Is and errors.As can inspect both the cause and the context error.
+// Errors returned by the work are returned unchanged.
 func (s *Service) Serve(ctx context.Context, req Request) (Response, error) {
+	var d details
+	return s.run(ctx, req, &d)
+}
+
+// details holds the details of a call of Serve that are collected along the way.
+type details struct {
+	// workDuration is the time from launching the work until its outcome was received, or until the context was done.
+	workDuration time.Duration
+	// queueWait is the time spent waiting for the rate limiter and for a slot of the concurrency limit, until the
+	// slot was acquired or the context was done.
+	queueWait time.Duration
+	// stop makes Serve return ErrStopped when closed. It is nil, so it never fires, unless ServeWithStop is used.
+	stop <-chan struct{}
+	// partial is true if the response is a partial result of the work. See WithPartialResults.
+	partial bool
+	// cause is the original error replaced by the fallback, nil if the fallback was not called.
+	cause error
+}
+
+// SetMeta sets the value of the key in the Meta of the request, creating the Meta on the first write.
+func (r *Request) SetMeta(key string, value any) {
+	if r.Meta == nil {
+		r.Meta = make(map[string]any)
+	}
+	r.Meta[key] = value
+}
+
+// withOwnMeta returns the request with its own copy of the Meta, for a goroutine serving the request concurrently
+// with the rest of the layers. A nil Meta stays nil.
+func withOwnMeta(req Request) Request {
+	req.Meta = maps.Clone(req.Meta)
+
+	return req
+}
+
+// run handles the request, recording the observability data (traces, metrics and logs), and fills the details.
+func (s *Service) run(ctx context.Context, req Request, d *details) (Response, error) {
+	// Reject the request if the Service is closed.
+	if err := s.enter(); err != nil {
+		return Response{}, err
+	}
+	defer s.inflight.Done()
+
+	if req.Meta == nil && s.validate != nil {
+		req.Meta = make(map[string]any)
+	}
+	ctx, requestID := s.ensureRequestID(ctx)
+	start := s.clock.Now()
+	var span trace.Span
+	if s.tracer != nil {
+		ctx, span = s.startSpan(ctx, req)
+	}
+
+	var res Response
+	var err error
+	if report := s.mirror(ctx, req); report != nil {
+		res, err = s.handleMirrored(ctx, req, d, report)
+	} else {
+		res, err = s.handle(ctx, req, d)
+	}
+
+	if span != nil {
+		endSpan(span, err)
+	}
+	elapsed := s.clock.Now().Sub(start)
+	class := s.classify(err)
+	if s.metrics != nil {
+		s.metrics.observe(elapsed, class)
+	}
+	if s.logger != nil && s.shouldLog(err) {
+		s.logger(ctx, LogEvent{
+			Request:   req,
+			Duration:  elapsed,
+			QueueWait: d.queueWait,
+			Outcome:   class,
+			Err:       err,
+			RequestID: requestID,
+			Name:      s.name,
+			Cause:     d.cause,
+		})
+	}
+	// Re-raise the panic of the work, if asked to.
+	if pe := s.repanicked(err); pe != nil {
+		panic(pe.Value)
+	}
+
+	return res, err
+}
+
+// handle serves the request from the cache, or from an in-flight identical request, or by launching the work,
+// and replaces any error with the fallback response.
+func (s *Service) handle(ctx context.Context, req Request, d *details) (Response, error) {
+	// Reject the malformed requests before spending any resources.
+	if err := s.validateRequest(req); err != nil {
+		return Response{}, err
+	}
+	// Reject the unbounded requests, if a deadline is required.
+	if err := s.checkDeadline(ctx); err != nil {
+		return Response{}, err
+	}
+	// Return the cached response, or the cached error, if there is one.
+	if s.cache != nil {
+		entry, ok, err := s.cache.get(ctx, req)
+		if err != nil {
+			return Response{}, err
+		}
+		if ok {
+			if entry.err != nil && s.fallback != nil {
+				d.cause = entry.err
+				return s.fallBack(ctx, req, entry.err)
+			}
+			return entry.res, entry.err
+		}
+	}
+
+	var res Response
+	var err error
+	if s.flights != nil {
+		// Share the outcome of an in-flight identical request, if there is one.
+		res, err = s.flights.do(ctx, req, d, s.serve)
+	} else {
+		res, err = s.serve(ctx, req, d)
+	}
+	if err == nil && s.transform != nil {
+		res, err = s.transform(ctx, req, res)
+	}
+	if err == nil {
+		res, err = s.compress(ctx, res)
+	}
+	if err == nil && s.cache != nil && !d.partial {
+		if cerr := s.cache.set(ctx, req, res); cerr != nil {
+			return Response{}, cerr
+		}
+	}
+	// A panic to re-raise in Serve is neither cached nor replaced.
+	if s.repanicked(err) != nil {
+		return Response{}, err
+	}
+	if err != nil && s.cache != nil && s.negativeTTL > 0 && s.negativeShouldCache(err) {
+		s.cache.setErr(req, err, s.negativeTTL)
+	}
+	// Replace the error with the fallback response, if there is a fallback.
+	if err != nil && s.fallback != nil {
+		d.cause = err
+		return s.fallBack(ctx, req, err)
+	}
+
+	return res, err
+}
+
+// serve launches the work and waits for its outcome or the cancellation of the context.
+func (s *Service) serve(ctx context.Context, req Request, d *details) (Response, error) {
+	queued := s.clock.Now()
+	// Bound the call with the timeout of the Service, capped by the maximum deadline, if there is any of them.
+	// The earliest of the deadlines applies.
+	timeout := s.timeout
+	if s.maxDeadline > 0 && (timeout <= 0 || s.maxDeadline < timeout) {
+		timeout = s.maxDeadline
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	// Bound the call with the timeout of the request, if there is one.
+	if s.requestTimeout != nil {
+		if d := s.requestTimeout(req); d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+	}
+	// Bound the call with the default timeout of the Service, if there is one and the context has no deadline.
+	if _, ok := ctx.Deadline(); !ok && s.defaultTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.defaultTimeout)
+		defer cancel()
+	}
+	// Don't launch work that can't possibly finish before the deadline.
+	if err := s.checkBudget(ctx); err != nil {
+		return Response{}, err
+	}
+	// Don't launch work while the Service is degraded, for a fraction of the requests.
+	if err := s.shed(); err != nil {
+		return Response{}, err
+	}
+	// Don't launch the work if the caller has already stopped waiting for it.
+	if err := d.stopped(); err != nil {
+		return Response{}, err
+	}
+	// Wait for the rate limiters, if there are any, before launching the work.
+	if err := s.limit(ctx); err != nil {
+		return Response{}, err
+	}
+	if err := s.limitKey(ctx, req); err != nil {
+		return Response{}, err
+	}
+	// Take a slot of the concurrency limit, if there is one, before launching the work.
+	err := s.acquire(ctx, req.Priority)
+	d.queueWait = s.clock.Now().Sub(queued)
+	if s.metrics != nil {
+		s.metrics.queueWait.Observe(d.queueWait.Seconds())
+	}
+	if err != nil {
+		return Response{}, err
+	}
+
+	// Cancel the context of the work as soon as we stop waiting for it, if asked to.
+	if s.workContext {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+	}
+
+	// Add the values of the Service to the context of the work, if there are any.
+	if len(s.ctxValues) > 0 {
+		ctx = valuesContext{Context: ctx, values: s.ctxValues, override: s.ctxOverride}
+	}
+	// Let the work report partial results, if asked to.
+	var p *partial
+	if s.partialResults {
+		p = &partial{}
+		ctx = context.WithValue(ctx, partialKey{}, p)
+	}
+
 	// Use buffered channel to avoid goroutine leak in case the context gets cancelled
 	// Read this excellent article for more details:
 	// https://www.ardanlabs.com/blog/2018/11/goroutine-leaks-the-forgotten-sender.html
-	resCh := make(chan Response, 1)
-	errCh := make(chan error, 1)
+	// The channel is taken from a pool, in order to save an allocation per call.
+	resultCh := s.getResultChan()
 
-	go func() {
-		// Do the work.
-		// In case of an error send the error in the errCh and return
-		resp, err := s.work()
-		if err != nil {
-			errCh <- err
-			return
+	// Measure the duration of the work, from launching it until Serve stops waiting for it.
+	start := s.clock.Now()
+	defer func() {
+		d.workDuration = s.clock.Now().Sub(start)
+		if s.shedder != nil {
+			s.shedder.record(d.workDuration)
 		}
-
-		// In case of happy path send the actual response in the resCh channel
-		resCh <- resp
 	}()
-	// Select will block until there is a errCh or resCh receives a message or the context is cancelled
+
+	// The work may keep running after Serve has returned, while the logger reads the request.
+	req = withOwnMeta(req)
+	// Track the work until it returns, even if Serve has already returned, so that Close can wait for it.
+	s.inflight.Add(1)
+	job := func() {
+		defer s.inflight.Done()
+		// Free the slot of the concurrency limit when the work is done, even if Serve has already returned.
+		defer s.release()
+		if s.onGoroutineStart != nil {
+			s.onGoroutineStart()
+		}
+		if s.onGoroutineEnd != nil {
+			defer s.onGoroutineEnd()
+		}
+
+		// Do the work, retrying it if needed, and send its outcome in the resultCh
+		s.traceEvent(ctx, EventWorkStart)
+		resp, err := s.do(ctx, req)
+		s.traceEvent(ctx, EventWorkFinish)
+		resultCh <- result{res: resp, err: err}
+	}
+	// Run the work on a new goroutine, or on a worker of the pool if there is one.
+	if err := s.launch(ctx, job); err != nil {
+		s.inflight.Done()
+		s.release()
+		s.putResultChan(resultCh)
+		return Response{}, err
+	}
+	// Select will block until the resultCh receives the outcome of the work or the context is cancelled
 	// due to a timeout, deadline on direct cancellation (using the cancel function)
 	select {
-	case err := <-errCh:
-		return Response{}, err
-	case res := <-resCh:
-		return res, nil
+	case r := <-resultCh:
+		// The channel is empty again and the work won't send anything else, so it can be reused.
+		s.putResultChan(resultCh)
+		if r.err != nil {
+			return Response{}, r.err
+		}
+		return r.res, nil
 	case <-ctx.Done():
-		return Response{}, ctx.Err()
+		// The work may still send its outcome, so the channel is reused only after discardAbandoned receives it.
+		// Release the bodies of the abandoned work when it returns.
+		s.inflight.Add(1)
+		go s.discardAbandoned(req, resultCh, s.clock.Now())
+		// Return the latest partial result instead of nothing, if there is one.
+		if res, ok := p.get(); ok {
+			d.partial = true
+			return res, nil
+		}
+		return Response{}, contextErr(ctx)
+	case <-d.stop:
+		// Abandon the work, exactly like on the cancellation of the context.
+		s.inflight.Add(1)
+		go s.discardAbandoned(req, resultCh, s.clock.Now())
+		return Response{}, ErrStopped
 	}
 }
+
+// typedResult is the outcome of a call of the work, or of anything else returning a response and an error, so that
+// both can be sent together on a single channel.
+type typedResult[Res any] struct {
+	res Res
+	err error
+}
+
+// result is the typedResult of the work of a Service.
+type result = typedResult[Response]
+
+// resultChans is the default pool of the channels receiving the outcome of the work, which has a single sender.
+// See WithResultPool.
+var resultChans = sync.Pool{
+	New: func() any {
+		return resultChan(1)
+	},
+}
@@ -24,7 +24,7 @@ func TestService_Serve_Success(t *testing.T) {
 		t.Errorf("Serve() should not return an error, go %v", err)
 	}
 
-	wantResp := Response{"success"}
+	wantResp := Response{Data: "success"}
 	if !reflect.DeepEqual(response, wantResp) {
 		t.Errorf("Serve() got response %v, wanted %v", response, wantResp)
 	}
@@ -53,6 +53,28 @@ func TestService_Serve_Error(t *testing.T) {
 	}
 }
 
+// Test case for a work error arriving before the deadline. The work error is returned instead of the context error,
+// and the response returned along with the error is discarded.
+func TestService_Serve_ErrorBeforeDeadline(t *testing.T) {
+	wantErr := errors.New("error")
+	srv := NewServiceCtx(func(ctx context.Context, req Request) (Response, error) {
+		time.Sleep(10 * time.Millisecond)
+		return Response{Data: "partial"}, wantErr
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	response, err := srv.Serve(ctx, Request{})
+
+	if err != wantErr {
+		t.Errorf("Serve() got err %v, wanted %v", err, wantErr)
+	}
+	if !reflect.DeepEqual(response, Response{}) {
+		t.Errorf("Serve() got response %v, wanted %v", response, Response{})
+	}
+}
+
 // Test case for service timeout. Context timed out before the service finished serving the request.
 func TestService_Serve_Timeout(t *testing.T) {
 
@@ -75,3 +97,83 @@ func TestService_Serve_Timeout(t *testing.T) {
 		t.Errorf("Serve() got response %v, wanted %v", response, wantResp)
 	}
 }
+
+// Test case for the context aware work. The work receives the context and the request passed to Serve.
+func TestService_ServeCtx_PassesContextAndRequest(t *testing.T) {
+	type key struct{}
+	var gotValue interface{}
+	var gotReq Request
+	srv := NewServiceCtx(func(ctx context.Context, req Request) (Response, error) {
+		gotValue = ctx.Value(key{})
+		gotReq = req
+		return Response{Data: "success"}, nil
+	})
+
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	req := Request{Data: "request"}
+
+	response, err := srv.Serve(ctx, req)
+
+	if err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+
+	wantResp := Response{Data: "success"}
+	if !reflect.DeepEqual(response, wantResp) {
+		t.Errorf("Serve() got response %v, wanted %v", response, wantResp)
+	}
+	if gotValue != "value" {
+		t.Errorf("work got context value %v, wanted %v", gotValue, "value")
+	}
+	if !reflect.DeepEqual(gotReq, req) {
+		t.Errorf("work got request %v, wanted %v", gotReq, req)
+	}
+}
+
+// Test case for the context aware work on timeout. The work sees the cancellation and stops.
+func TestService_ServeCtx_WorkHonorsCancellation(t *testing.T) {
+	workErr := make(chan error, 1)
+	srv := NewServiceCtx(func(ctx context.Context, req Request) (Response, error) {
+		select {
+		case <-time.After(2000 * time.Millisecond):
+			workErr <- nil
+			return Response{Data: "success"}, nil
+		case <-ctx.Done():
+			workErr <- ctx.Err()
+			return Response{}, ctx.Err()
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if err := <-workErr; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("work got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+}
+
+// Test case for the request aware work. The same service serves different requests, and each request
+// reaches the work unchanged.
+func TestService_ServeRequest_PassesRequest(t *testing.T) {
+	srv := NewRequestService(func(req Request) (Response, error) {
+		return Response{Data: "echo " + req.Data}, nil
+	})
+
+	for _, data := range []string{"first", "second", ""} {
+		response, err := srv.Serve(context.Background(), Request{Data: data})
+
+		if err != nil {
+			t.Errorf("Serve() should not return an error, got %v", err)
+		}
+
+		wantResp := Response{Data: "echo " + data}
+		if !reflect.DeepEqual(response, wantResp) {
+			t.Errorf("Serve() got response %v, wanted %v", response, wantResp)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Package servicetest provides a conformance test for the implementations of service.Server, e.g. a Service with
+// options or a chain of middleware, checking that they keep the contract of the Server they wrap.
+package servicetest
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/psampaz/service"
+)
+
+// ErrWork is the error returned by Work in the error case of RunServerConformance.
+var ErrWork = errors.New("servicetest: work error")
+
+// ErrNoBehavior is the error returned by Work when it is called with a context that doesn't come from
+// RunServerConformance, e.g. because a middleware replaced the context of the caller instead of deriving from it.
+var ErrNoBehavior = errors.New("servicetest: the context carries no behavior")
+
+// returnTimeout is the time Serve has to return once it should, before the conformance test gives up on it.
+const returnTimeout = 5 * time.Second
+
+// closer is a Server that must be closed to release its resources, like Service.
+type closer interface {
+	Close(ctx context.Context) error
+}
+
+// behaviorKey is the context key of the behavior of Work.
+type behaviorKey struct{}
+
+// behavior is what Work does in a case of the conformance test.
+type behavior struct {
+	res service.Response
+	err error
+	// clock is the clock the work sleeps on, if it is slow. It is never advanced, so a slow work returns only when
+	// its context is done, however slow the machine running the test is.
+	clock *service.FakeClock
+	// started is closed when the work is called for the first time.
+	started chan struct{}
+	once    sync.Once
+}
+
+// newBehavior returns the behavior of a work returning the response and the error, or of a slow work returning
+// only when its context is done.
+func newBehavior(res service.Response, err error, slow bool) *behavior {
+	b := &behavior{res: res, err: err, started: make(chan struct{})}
+	if slow {
+		b.clock = service.NewFakeClock(time.Now())
+	}
+
+	return b
+}
+
+// Work is the work of the Server under test of RunServerConformance. It behaves as every case of the conformance
+// test needs, according to the context it gets: it succeeds, fails with ErrWork, or blocks until its context is
+// done and returns the context error. Register it as the work of the Server under test, e.g.
+// service.NewServiceWithOptions(servicetest.Work, opts...), or as the innermost Server of a middleware chain, e.g.
+// service.Chain(service.ServerFunc(servicetest.Work), mw...).
+func Work(ctx context.Context, req service.Request) (service.Response, error) {
+	b, ok := ctx.Value(behaviorKey{}).(*behavior)
+	if !ok {
+		return service.Response{}, ErrNoBehavior
+	}
+	b.once.Do(func() { close(b.started) })
+
+	if b.clock != nil {
+		select {
+		case <-b.clock.After(time.Hour):
+		case <-ctx.Done():
+			return service.Response{}, ctx.Err()
+		}
+	}
+
+	return b.res, b.err
+}
+
+// RunServerConformance runs the conformance test of a Server, as subtests of t: a successful call returns the
+// Response of the work, a failed call returns an error wrapping the error of the work, a call whose context exceeds
+// its deadline returns an error wrapping context.DeadlineExceeded, and a call whose context gets cancelled returns
+// an error wrapping context.Canceled. Serve must return promptly when the context is done, and no goroutine may be
+// left running once Serve has returned, or once the Server is closed if it has a Close(ctx) method like Service.
+// factory is called once per case and must return a new Server calling Work (see Work).
+// Middleware retrying, hedging or falling back on errors may break the error case by design; the rest of the cases
+// hold for every Server.
+func RunServerConformance(t *testing.T, factory func() service.Server) {
+	t.Helper()
+
+	t.Run("success", func(t *testing.T) {
+		want := service.Response{Data: "servicetest: response"}
+		res, err := serve(context.Background(), t, factory, newBehavior(want, nil, false))
+		if err != nil || res.Data != want.Data {
+			t.Errorf("Serve() got %v and err %v, wanted %v and err %v", res, err, want, nil)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := serve(context.Background(), t, factory, newBehavior(service.Response{}, ErrWork, false))
+		if !errors.Is(err, ErrWork) {
+			t.Errorf("Serve() got err %v, wanted %v", err, ErrWork)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := serve(ctx, t, factory, newBehavior(service.Response{}, nil, true))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		b := newBehavior(service.Response{}, nil, true)
+		go func() {
+			// Cancel only once the work is running, so that the cancellation reaches it.
+			select {
+			case <-b.started:
+			case <-time.After(returnTimeout):
+			}
+			cancel()
+		}()
+		_, err := serve(ctx, t, factory, b)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Serve() got err %v, wanted %v", err, context.Canceled)
+		}
+	})
+}
+
+// serve calls Serve on a new Server with a context carrying the behavior of the work, and checks that it returns
+// promptly and leaves no goroutine behind.
+func serve(ctx context.Context, t *testing.T, factory func() service.Server, b *behavior) (service.Response, error) {
+	t.Helper()
+
+	before := runtime.NumGoroutine()
+	server := factory()
+
+	type result struct {
+		res service.Response
+		err error
+	}
+	results := make(chan result, 1)
+	go func() {
+		res, err := server.Serve(context.WithValue(ctx, behaviorKey{}, b), service.Request{})
+		results <- result{res: res, err: err}
+	}()
+
+	var r result
+	select {
+	case r = <-results:
+	case <-time.After(returnTimeout):
+		t.Fatalf("Serve() did not return within %v", returnTimeout)
+	}
+
+	if c, ok := server.(closer); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), returnTimeout)
+		defer cancel()
+		if err := c.Close(ctx); err != nil {
+			t.Errorf("Close() got err %v, wanted %v", err, nil)
+		}
+	}
+	checkLeaks(t, before)
+
+	return r.res, r.err
+}
+
+// checkLeaks fails the test if there are more goroutines running than before, once the goroutines that are about
+// to finish have had the time to do so.
+func checkLeaks(t *testing.T, before int) {
+	t.Helper()
+
+	deadline := time.Now().Add(returnTimeout)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Errorf("%d goroutines are still running, wanted %d", runtime.NumGoroutine(), before)
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
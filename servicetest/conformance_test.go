@@ -0,0 +1,31 @@
+package servicetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/psampaz/service"
+)
+
+// Test case for the conformance of a Service with the features that keep the contract of the work
+func TestRunServerConformance_Service(t *testing.T) {
+	RunServerConformance(t, func() service.Server {
+		return service.NewServiceWithOptions(Work,
+			service.WithTimeout(time.Second),
+			service.WithMaxConcurrency(2),
+			service.WithRetry(2, 0),
+			service.WithHedging(time.Millisecond, 1),
+		)
+	})
+}
+
+// Test case for the conformance of a chain of middleware
+func TestRunServerConformance_Middleware(t *testing.T) {
+	RunServerConformance(t, func() service.Server {
+		return service.Chain(service.ServerFunc(Work),
+			service.TimeoutMiddleware(time.Second),
+			service.LoggingMiddleware(func(ctx context.Context, event service.LogEvent) {}),
+		)
+	})
+}
@@ -0,0 +1,95 @@
+package service
+
+import "context"
+
+// WithShadow is an option that mirrors a fraction sampleRate of the requests to the shadow Server, e.g. a new
+// implementation of the work, in order to test it with real traffic without affecting the callers.
+// The shadow is called asynchronously, on its own goroutine, and its outcome is discarded, or passed to the compare
+// function of WithShadowCompare, so it never blocks nor changes the outcome of Serve. Its context carries the values
+// and the deadline of the context of the caller, but it is not cancelled when Serve returns, so a slow shadow
+// doesn't affect the caller and vice versa. The Body of the request is not mirrored, since it can be read only once,
+// and the Body of the shadow response is closed after the compare function returns. A panic of the shadow is
+// recovered like a panic of the work. Close waits for the shadow calls in flight.
+// The sampling is deterministic, like the one of WithLogSampling. A sampleRate of 1 or more mirrors every request.
+func WithShadow(shadow Server, sampleRate float64) Option {
+	return func(s *Service) {
+		s.shadow = shadow
+		s.shadowSampler = &sampler{rate: sampleRate}
+	}
+}
+
+// WithShadowCompare is an option that calls compare with the outcome of Serve and of the shadow for every mirrored
+// request (see WithShadow), e.g. to report their differences. It is called on the goroutine of the shadow once both
+// have returned, so it may be called concurrently for different requests and must be safe for concurrent use.
+// It must not read the Body of the primary response, which is owned by the caller of Serve.
+func WithShadowCompare(compare func(primary, shadow Response, primaryErr, shadowErr error)) Option {
+	return func(s *Service) {
+		s.shadowCompare = compare
+	}
+}
+
+// mirror sends a copy of the request to the shadow, if the request is sampled, and returns the function reporting
+// the outcome of Serve for the comparison. It returns nil if the request is not mirrored.
+func (s *Service) mirror(ctx context.Context, req Request) func(res Response, err error) {
+	if s.shadow == nil || !s.shadowSampler.sample() {
+		return nil
+	}
+
+	// Keep the values and the deadline of the caller, but not its cancellation.
+	var shadowCtx context.Context
+	var cancel context.CancelFunc
+	if deadline, ok := ctx.Deadline(); ok {
+		shadowCtx, cancel = context.WithDeadline(context.WithoutCancel(ctx), deadline)
+	} else {
+		shadowCtx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+	}
+	req.Body = nil
+	req = withOwnMeta(req)
+	primary := resultChan(1)
+
+	s.inflight.Add(1)
+	go func() {
+		defer s.inflight.Done()
+		defer cancel()
+
+		res, err := s.callShadow(shadowCtx, req)
+		if s.shadowCompare != nil || s.onMismatch != nil {
+			p := <-primary
+			if s.shadowCompare != nil {
+				s.shadowCompare(p.res, res, p.err, err)
+			}
+			if p.err == nil && err == nil {
+				s.compareResponses(req, p.res, res)
+			}
+		}
+		DrainAndClose(res.Body)
+	}()
+
+	return func(res Response, err error) {
+		primary <- result{res: res, err: err}
+	}
+}
+
+// handleMirrored handles the request and reports its outcome to the shadow. If a hook panics, e.g. the validator or
+// the fallback, ErrPanic is reported instead while the panic goes on, so that the shadow is always released.
+func (s *Service) handleMirrored(ctx context.Context, req Request, d *details, report func(res Response, err error)) (Response, error) {
+	returned := false
+	defer func() {
+		if !returned {
+			report(Response{}, ErrPanic)
+		}
+	}()
+
+	res, err := s.handle(ctx, req, d)
+	returned = true
+	report(res, err)
+
+	return res, err
+}
+
+// callShadow calls the shadow, converting a panic of the shadow to an error.
+func (s *Service) callShadow(ctx context.Context, req Request) (res Response, err error) {
+	defer s.recoverWork(&err)
+
+	return s.shadow.Serve(ctx, req)
+}
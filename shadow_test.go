@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Test case for a mirrored request. The shadow is called and compared, while the primary response is unaffected by
+// the failing and slow shadow.
+func TestService_Serve_Shadow(t *testing.T) {
+	shadowErr := errors.New("shadow error")
+	shadow := &TestService{Res: Response{Data: "shadow"}, Err: shadowErr, DelayReponse: 200 * time.Millisecond}
+	type comparison struct {
+		primary, shadow       Response
+		primaryErr, shadowErr error
+	}
+	compared := make(chan comparison, 1)
+	srv := NewServiceWithOptions((&TestService{Res: Response{Data: "primary"}}).Serve,
+		WithShadow(shadow, 1),
+		WithShadowCompare(func(primary, shadow Response, primaryErr, shadowErr error) {
+			compared <- comparison{primary: primary, shadow: shadow, primaryErr: primaryErr, shadowErr: shadowErr}
+		}),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	start := time.Now()
+	res, err := srv.Serve(ctx, Request{Data: "req"})
+	// Cancelling the context of the caller doesn't affect the shadow.
+	cancel()
+
+	if err != nil || res.Data != "primary" {
+		t.Errorf("Serve() got (%v, %v), wanted (%v, %v)", res, err, Response{Data: "primary"}, nil)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Serve() returned after %v, wanted it not to wait for the shadow", elapsed)
+	}
+	select {
+	case got := <-compared:
+		want := comparison{primary: Response{Data: "primary"}, shadow: Response{Data: "shadow"}, shadowErr: shadowErr}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("compare got %+v, wanted %+v", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("compare was not called")
+	}
+	if got := shadow.Snapshot().Request; got.Data != "req" {
+		t.Errorf("shadow got request %v, wanted %v", got, Request{Data: "req"})
+	}
+}
+
+// Test case for the sample rate of the shadow. Only a fraction of the requests is mirrored.
+func TestService_Serve_ShadowSampleRate(t *testing.T) {
+	shadow := &TestService{Synchronous: true}
+	srv := NewServiceWithOptions((&TestService{}).Serve, WithShadow(shadow, 0.5))
+
+	for i := 0; i < 10; i++ {
+		srv.Serve(context.Background(), Request{})
+	}
+	if err := srv.Close(context.Background()); err != nil {
+		t.Fatalf("Close() got err %v, wanted %v", err, nil)
+	}
+
+	if calls := shadow.Snapshot().Calls; calls != 5 {
+		t.Errorf("shadow got %d calls, wanted %d", calls, 5)
+	}
+}
+
+// Test case for a panicking shadow. The panic is recovered and the primary response is unaffected.
+func TestService_Serve_ShadowPanic(t *testing.T) {
+	shadowErrs := make(chan error, 1)
+	srv := NewServiceWithOptions((&TestService{}).Serve,
+		WithShadow(ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+			panic("boom")
+		}), 1),
+		WithShadowCompare(func(primary, shadow Response, primaryErr, shadowErr error) {
+			shadowErrs <- shadowErr
+		}),
+	)
+
+	if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+		t.Errorf("Serve() got err %v, wanted %v", err, nil)
+	}
+	var panicErr *PanicError
+	if err := <-shadowErrs; !errors.As(err, &panicErr) {
+		t.Errorf("compare got shadow err %v, wanted a %T", err, panicErr)
+	}
+}
+
+// Test case for a panicking hook of a mirrored request. The panic goes on to the caller, the shadow is released with
+// ErrPanic as the primary error and Close doesn't wait forever for it.
+func TestService_Serve_ShadowHookPanic(t *testing.T) {
+	primaryErrs := make(chan error, 1)
+	srv := NewServiceWithOptions((&TestService{}).Serve,
+		WithShadow(&TestService{}, 1),
+		WithShadowCompare(func(primary, shadow Response, primaryErr, shadowErr error) {
+			primaryErrs <- primaryErr
+		}),
+		WithValidator(func(req Request) error {
+			panic("boom")
+		}),
+	)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("Serve() panicked with %v, wanted %v", r, "boom")
+			}
+		}()
+		srv.Serve(context.Background(), Request{})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := srv.Close(ctx); err != nil {
+		t.Fatalf("Close() got err %v, wanted %v", err, nil)
+	}
+	if err := <-primaryErrs; !errors.Is(err, ErrPanic) {
+		t.Errorf("compare got primary err %v, wanted %v", err, ErrPanic)
+	}
+}
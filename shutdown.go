@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrClosed is the error returned by Serve after Close has been called.
+var ErrClosed = errors.New("service: closed")
+
+// Close stops the Service from accepting new requests and waits for the requests being served to finish, including
+// any work still running after its Serve call has returned because the context got cancelled.
+// Calls of Serve after Close return ErrClosed immediately.
+// Close returns the context error, wrapped, if the context gets cancelled before the requests finish.
+// Calling Close more than once is safe, and every call waits for the requests to finish.
+// The workers of WithWorkerPool are stopped once every request has finished, even if Close has already returned.
+func (s *Service) Close(ctx context.Context) error {
+	s.closeMu.Lock()
+	s.closed = true
+	s.closeMu.Unlock()
+
+	// Use buffered channel to avoid goroutine leak in case the context gets cancelled
+	done := make(chan struct{}, 1)
+	go func() {
+		s.inflight.Wait()
+		// Nothing can be submitted to the worker pool anymore, so stop the workers.
+		if s.pool != nil {
+			s.pool.close()
+		}
+		done <- struct{}{}
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("service: waiting for the requests to finish: %w", ctx.Err())
+	}
+}
+
+// enter registers a new request, unless the Service is closed in which case it returns ErrClosed.
+// Every successful call of enter must be followed by a call of s.inflight.Done.
+func (s *Service) enter() error {
+	// The lock makes sure that no request is added after Close has started waiting.
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+	s.inflight.Add(1)
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test case for closing a Service while slow work is running. Close waits for the work to complete, and new
+// requests are rejected.
+func TestService_Close(t *testing.T) {
+	var completed int64
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		time.Sleep(100 * time.Millisecond)
+		atomic.StoreInt64(&completed, 1)
+		return Response{Data: "success"}, nil
+	})
+
+	served := make(chan error, 1)
+	go func() {
+		_, err := srv.Serve(context.Background(), Request{})
+		served <- err
+	}()
+	waitFor(t, func() bool { return srv.Attempts() == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Close(ctx); err != nil {
+		t.Errorf("Close() should not return an error, got %v", err)
+	}
+
+	if atomic.LoadInt64(&completed) != 1 {
+		t.Errorf("Close() returned before the work completed")
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+
+	start := time.Now()
+	if _, err := srv.Serve(context.Background(), Request{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Serve() got err %v, wanted %v", err, ErrClosed)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Serve() returned after %v, wanted it to return immediately", elapsed)
+	}
+	if srv.Attempts() != 1 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 1)
+	}
+}
+
+// Test case for closing a Service with work abandoned after a timeout. Close waits for the abandoned work too.
+func TestService_Close_AbandonedWork(t *testing.T) {
+	var completed int64
+	srv := NewService(func() (Response, error) {
+		time.Sleep(100 * time.Millisecond)
+		atomic.StoreInt64(&completed, 1)
+		return Response{Data: "success"}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	srv.Serve(ctx, Request{})
+
+	if err := srv.Close(context.Background()); err != nil {
+		t.Errorf("Close() should not return an error, got %v", err)
+	}
+	if atomic.LoadInt64(&completed) != 1 {
+		t.Errorf("Close() returned before the abandoned work completed")
+	}
+}
+
+// Test case for closing a Service with a short deadline. Close returns the context error.
+func TestService_Close_Timeout(t *testing.T) {
+	srv := NewService(func() (Response, error) {
+		time.Sleep(200 * time.Millisecond)
+		return Response{Data: "success"}, nil
+	})
+	go srv.Serve(context.Background(), Request{})
+	waitFor(t, func() bool { return srv.Attempts() == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := srv.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithSingleFlight is an option that deduplicates concurrent identical requests: while a request is being served,
+// identical requests don't launch the work again but wait for the outcome of the in-flight request, and all of them
+// receive the same Response and error. keyFn returns the key of a request, and requests with the same key are
+// considered identical. A nil keyFn uses the key function of WithKeyFunc, or Fingerprint by default.
+// Every waiter honors its own context: a waiter whose context gets cancelled stops waiting and returns its context
+// error, without affecting the rest of the waiters. For this reason the shared call doesn't run with the context of
+// the caller that started it, but with a context that keeps its values and gets cancelled only when every waiter
+// has stopped waiting.
+func WithSingleFlight(keyFn func(Request) string) Option {
+	return func(s *Service) {
+		s.flights = &flightGroup{
+			keyFn: keyFn,
+			calls: make(map[string]*flight),
+		}
+	}
+}
+
+// flightGroup keeps track of the in-flight calls, safe for concurrent use.
+type flightGroup struct {
+	keyFn func(Request) string
+
+	// mu guards calls and the waiters of every call.
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// flight is an in-flight call shared by one or more waiters.
+type flight struct {
+	// done is closed when the call returns. res, err and d must be read only after that.
+	done chan struct{}
+	res  Response
+	err  error
+	d    details
+	// waiters is the number of callers still waiting for the call.
+	waiters int
+	// cancel cancels the context of the call.
+	cancel context.CancelFunc
+}
+
+// do calls fn for the request, unless there is an in-flight call for an identical request, in which case it waits
+// for the outcome of that call, or for the context to be cancelled. The details of the call are copied to d.
+func (g *flightGroup) do(ctx context.Context, req Request, d *details,
+	fn func(ctx context.Context, req Request, d *details) (Response, error)) (Response, error) {
+	key := g.keyFn(req)
+
+	g.mu.Lock()
+	f, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(detachedContext{parent: ctx})
+		f = &flight{
+			done:   make(chan struct{}),
+			cancel: cancel,
+		}
+		g.calls[key] = f
+		// The call may outlive the caller, which keeps using its request.
+		callReq := withOwnMeta(req)
+		go func() {
+			f.res, f.err = fn(callCtx, callReq, &f.d)
+			g.forget(key, f)
+			cancel()
+			close(f.done)
+		}()
+	}
+	f.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		*d = f.d
+		return f.res, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		last := f.waiters == 0
+		g.mu.Unlock()
+		// Nobody waits for the call anymore, so cancel it and let the next identical request start a new one.
+		if last {
+			g.forget(key, f)
+			f.cancel()
+		}
+		return Response{}, contextErr(ctx)
+	}
+}
+
+// forget removes the call from the in-flight calls, unless it has been replaced by a newer call.
+func (g *flightGroup) forget(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+}
+
+// detachedContext is a context that keeps the values of its parent, but not its deadline and cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+// Deadline returns no deadline.
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done returns a nil channel, since the context is never cancelled.
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err returns nil, since the context is never cancelled.
+func (detachedContext) Err() error {
+	return nil
+}
+
+// Value returns the value of the parent context.
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
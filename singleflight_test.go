@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test case for 100 concurrent identical requests. The work is called once and every caller gets its response.
+func TestService_Serve_SingleFlight(t *testing.T) {
+	release := make(chan struct{})
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		<-release
+		return Response{Data: "response " + req.Data}, nil
+	}, WithSingleFlight(nil))
+
+	var wg sync.WaitGroup
+	responses := make(chan Response, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := srv.Serve(context.Background(), Request{Data: "a"})
+			if err != nil {
+				t.Errorf("Serve() should not return an error, got %v", err)
+			}
+			responses <- res
+		}()
+	}
+	waitFor(t, func() bool {
+		srv.flights.mu.Lock()
+		defer srv.flights.mu.Unlock()
+		f, ok := srv.flights.calls[Fingerprint(Request{Data: "a"})]
+		return ok && f.waiters == 100
+	})
+	close(release)
+	wg.Wait()
+	close(responses)
+
+	wantResp := Response{Data: "response a"}
+	for res := range responses {
+		if !reflect.DeepEqual(res, wantResp) {
+			t.Errorf("Serve() got response %v, wanted %v", res, wantResp)
+		}
+	}
+	if srv.Attempts() != 1 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 1)
+	}
+}
+
+// Test case for a waiter whose context gets cancelled. It stops waiting, while the other waiter gets the response.
+func TestService_Serve_SingleFlightOwnContext(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			return Response{Data: "success"}, nil
+		case <-ctx.Done():
+			return Response{}, ctx.Err()
+		}
+	}, WithSingleFlight(nil))
+
+	// The first caller starts the shared call and gives up early.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	first := make(chan error, 1)
+	go func() {
+		_, err := srv.Serve(ctx, Request{})
+		first <- err
+	}()
+	waitFor(t, func() bool { return srv.Attempts() == 1 })
+
+	response, err := srv.Serve(context.Background(), Request{})
+
+	if err := <-first; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+	wantResp := Response{Data: "success"}
+	if !reflect.DeepEqual(response, wantResp) {
+		t.Errorf("Serve() got response %v, wanted %v", response, wantResp)
+	}
+	if srv.Attempts() != 1 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 1)
+	}
+}
+
+// Test case for a shared call whose waiters have all stopped waiting. The call gets cancelled.
+func TestService_Serve_SingleFlightAbandoned(t *testing.T) {
+	workErr := make(chan error, 1)
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		<-ctx.Done()
+		workErr <- ctx.Err()
+		return Response{}, ctx.Err()
+	}, WithSingleFlight(nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	srv.Serve(ctx, Request{})
+
+	select {
+	case err := <-workErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("work got err %v, wanted %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("the abandoned shared call was not cancelled")
+	}
+}
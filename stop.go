@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrStopped is the error returned by ServeWithStop when the stop channel is closed before the work returns.
+var ErrStopped = errors.New("service: stopped")
+
+// ServeWithStop serves the request like Serve, but it also returns ErrStopped as soon as the stop channel is closed,
+// for integrating with code that signals the shutdown with a channel instead of a context. Whatever fires first
+// among the context, the stop channel and the work decides the outcome. Like on the cancellation of the context, the
+// work is abandoned when the stop channel is closed, and its context is cancelled only with WithWorkContext.
+// The stop channel is checked before launching the work and while waiting for it, but not while waiting for the rate
+// limiter or a concurrency slot, which are bounded by the context only. A nil stop channel never fires.
+func (s *Service) ServeWithStop(ctx context.Context, req Request, stop <-chan struct{}) (Response, error) {
+	d := details{stop: stop}
+	return s.run(ctx, req, &d)
+}
+
+// stopped returns ErrStopped if the stop channel of ServeWithStop is closed.
+func (d *details) stopped() error {
+	select {
+	case <-d.stop:
+		return ErrStopped
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test case for ServeWithStop, where the first of the stop channel, the context and the work decides the outcome
+func TestService_ServeWithStop(t *testing.T) {
+	tests := []struct {
+		name    string
+		stop    time.Duration
+		timeout time.Duration
+		delay   time.Duration
+		wantErr error
+	}{
+		{name: "stop wins", stop: 10 * time.Millisecond, timeout: time.Minute, delay: time.Minute, wantErr: ErrStopped},
+		{name: "context wins", stop: time.Minute, timeout: 10 * time.Millisecond, delay: time.Minute, wantErr: context.DeadlineExceeded},
+		{name: "work wins", stop: time.Minute, timeout: time.Minute, delay: 0, wantErr: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := &TestService{DelayReponse: tt.delay}
+			srv := NewServiceWithOptions(ts.Serve)
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			stop := make(chan struct{})
+			timer := time.AfterFunc(tt.stop, func() { close(stop) })
+			defer timer.Stop()
+
+			start := time.Now()
+			_, err := srv.ServeWithStop(ctx, Request{}, stop)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ServeWithStop() got err %v, wanted %v", err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("ServeWithStop() returned after %v, wanted about %v", elapsed, 10*time.Millisecond)
+			}
+		})
+	}
+}
+
+// Test case for a stop channel closed before the call. The work is not launched.
+func TestService_ServeWithStop_AlreadyStopped(t *testing.T) {
+	srv := NewServiceWithOptions((&TestService{}).Serve)
+	stop := make(chan struct{})
+	close(stop)
+
+	_, err := srv.ServeWithStop(context.Background(), Request{}, stop)
+
+	if err != ErrStopped {
+		t.Errorf("ServeWithStop() got err %v, wanted %v", err, ErrStopped)
+	}
+	if srv.Attempts() != 0 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 0)
+	}
+}
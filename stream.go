@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+)
+
+// StreamService is a service whose work produces a stream of responses instead of a single response.
+type StreamService struct {
+	// func representing the actual work that produces the stream of responses.
+	// It sends the responses on out, and returns when the stream is over or failed.
+	work func(ctx context.Context, req Request, out chan<- Response) error
+}
+
+// NewStreamService is a factory function/constructor for the StreamService.
+// The work must send every response on out in a select along with ctx.Done(), and return as soon as the context
+// gets cancelled. The work must not close out.
+func NewStreamService(work func(ctx context.Context, req Request, out chan<- Response) error) *StreamService {
+	return &StreamService{
+		work: work,
+	}
+}
+
+// ServeStream is the method of the StreamService that handles the request.
+// It returns a channel delivering the stream of responses and a channel delivering at most one error, the error
+// of the work or the context error in case of cancellation. Both channels are closed when the work returns or the
+// context gets cancelled, so the caller can range over the responses and then check for an error.
+// When the context gets cancelled the work is not waited for, but its pending responses are drained until it
+// returns, so no goroutine leaks even if the work sends without checking the context.
+func (s *StreamService) ServeStream(ctx context.Context, req Request) (<-chan Response, <-chan error) {
+	resCh := make(chan Response)
+	// Use buffered channel so that the error can be sent even if the caller stopped receiving
+	errCh := make(chan error, 1)
+
+	// The context of the work gets cancelled when the stream is over, whatever the reason is.
+	ctx, cancel := context.WithCancel(ctx)
+	out := make(chan Response)
+	// Use buffered channel to avoid goroutine leak in case the context gets cancelled
+	doneCh := make(chan error, 1)
+	go func() {
+		doneCh <- s.work(ctx, req, out)
+	}()
+
+	go func() {
+		defer cancel()
+
+		for {
+			select {
+			case res := <-out:
+				// Forward the response, unless the context gets cancelled while the caller doesn't receive.
+				select {
+				case resCh <- res:
+					continue
+				case <-ctx.Done():
+				}
+			case err := <-doneCh:
+				if err != nil {
+					errCh <- err
+				}
+				close(resCh)
+				close(errCh)
+				return
+			case <-ctx.Done():
+			}
+
+			// The context got cancelled.
+			errCh <- ctx.Err()
+			close(resCh)
+			close(errCh)
+			// Drain the pending responses until the work returns.
+			for {
+				select {
+				case <-out:
+				case <-doneCh:
+					return
+				}
+			}
+		}
+	}()
+
+	return resCh, errCh
+}
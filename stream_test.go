@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// Test case for the happy path. Every response is received and the channels get closed.
+func TestStreamService_ServeStream_Success(t *testing.T) {
+	srv := NewStreamService(func(ctx context.Context, req Request, out chan<- Response) error {
+		for _, data := range []string{"1", "2", "3"} {
+			select {
+			case out <- Response{Data: data}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+
+	resCh, errCh := srv.ServeStream(context.Background(), Request{})
+
+	var responses []Response
+	for res := range resCh {
+		responses = append(responses, res)
+	}
+	if err := <-errCh; err != nil {
+		t.Errorf("ServeStream() should not return an error, got %v", err)
+	}
+	wantResponses := []Response{{Data: "1"}, {Data: "2"}, {Data: "3"}}
+	if !reflect.DeepEqual(responses, wantResponses) {
+		t.Errorf("ServeStream() got responses %v, wanted %v", responses, wantResponses)
+	}
+}
+
+// Test case for a failing work. The responses sent before the failure are received, followed by the error.
+func TestStreamService_ServeStream_Error(t *testing.T) {
+	wantErr := errors.New("error")
+	srv := NewStreamService(func(ctx context.Context, req Request, out chan<- Response) error {
+		out <- Response{Data: "1"}
+		return wantErr
+	})
+
+	resCh, errCh := srv.ServeStream(context.Background(), Request{})
+
+	n := 0
+	for range resCh {
+		n++
+	}
+	if n != 1 {
+		t.Errorf("ServeStream() got %d responses, wanted %d", n, 1)
+	}
+	if err := <-errCh; err != wantErr {
+		t.Errorf("ServeStream() got err %v, wanted %v", err, wantErr)
+	}
+}
+
+// Test case for a timeout while the caller is not receiving. The channels get closed and no goroutine leaks,
+// even though the work sends without checking the context.
+func TestStreamService_ServeStream_Timeout(t *testing.T) {
+	before := runtime.NumGoroutine()
+	srv := NewStreamService(func(ctx context.Context, req Request, out chan<- Response) error {
+		for i := 0; i < 3; i++ {
+			time.Sleep(20 * time.Millisecond)
+			out <- Response{Data: "data"}
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	resCh, errCh := srv.ServeStream(ctx, Request{})
+	time.Sleep(50 * time.Millisecond)
+
+	for range resCh {
+	}
+	if err := <-errCh; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ServeStream() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("got %d goroutines after the work returned, wanted at most %d", after, before)
+	}
+}
+
+// Test case for the test stream service. It replays the responses and records the cancellation.
+func TestTestStreamService_ServeStream(t *testing.T) {
+	var srv StreamServer = &TestStreamService{
+		Responses: []Response{{Data: "1"}, {Data: "2"}, {Data: "3"}},
+		Delay:     20 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	resCh, errCh := srv.ServeStream(ctx, Request{Data: "request"})
+
+	n := 0
+	for range resCh {
+		n++
+	}
+	if err := <-errCh; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ServeStream() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+
+	recorder := srv.(*TestStreamService).Recorder
+	if n != 2 || recorder.Sent != 2 {
+		t.Errorf("got %d responses and %d recorded, wanted %d", n, recorder.Sent, 2)
+	}
+	if recorder.Request.Data != "request" || !recorder.CtxDeadlineExceeded {
+		t.Errorf("got recorder %+v, wanted the request and the deadline exceeded", recorder)
+	}
+}
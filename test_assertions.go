@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// AssertDeadlineExceeded fails the test if the context of the last call of Serve did not exceed its deadline.
+func (t *GenericTestService[Req, Res]) AssertDeadlineExceeded(tb testing.TB) {
+	tb.Helper()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.Recorder.CtxDeadlineExceeded {
+		tb.Errorf("TestService: the context should exceed its deadline, got context error %v", t.Recorder.CtxErr)
+	}
+}
+
+// AssertCancelled fails the test if the context of the last call of Serve was not cancelled.
+func (t *GenericTestService[Req, Res]) AssertCancelled(tb testing.TB) {
+	tb.Helper()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.Recorder.CtxCancelled {
+		tb.Errorf("TestService: the context should be cancelled, got context error %v", t.Recorder.CtxErr)
+	}
+}
+
+// AssertServed fails the test if Serve was not called, or if the request of the last call is not the wanted one.
+func (t *GenericTestService[Req, Res]) AssertServed(tb testing.TB, want Req) {
+	tb.Helper()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.Recorder.Calls == 0 {
+		tb.Errorf("TestService: the request %+v should be served, got no calls", want)
+		return
+	}
+	if !reflect.DeepEqual(t.Recorder.Request, want) {
+		tb.Errorf("TestService: got request %+v, wanted %+v", t.Recorder.Request, want)
+	}
+}
+
+// probeKey is the context key of the sentinel value of AssertContextPropagated.
+type probeKey struct{}
+
+// probes counts the calls of AssertContextPropagated, so that every call uses a different sentinel value.
+var probes atomic.Int64
+
+// ContextProbe is a work function for AssertContextPropagated: it returns the sentinel value of the context in
+// Response.Data, or an empty Response if the context doesn't carry it. Register it as the work of the Server under
+// test, e.g. NewServiceWithOptions(ContextProbe, opts...), or as the innermost Server of a middleware chain, e.g.
+// Chain(ServerFunc(ContextProbe), mw...).
+func ContextProbe(ctx context.Context, req Request) (Response, error) {
+	v, _ := ctx.Value(probeKey{}).(string)
+
+	return Response{Data: v}, nil
+}
+
+// AssertContextPropagated fails the test if the values of the context passed to Serve don't reach the work, e.g.
+// because a middleware replaced the context with context.Background() instead of deriving it from the context of
+// the caller. It calls Serve with a context carrying a sentinel value, and checks that ContextProbe, which must be
+// the work of the server, got it unchanged. It works with any Server that returns the Response.Data of the work
+// as is.
+func AssertContextPropagated(tb testing.TB, server Server) {
+	tb.Helper()
+
+	want := fmt.Sprintf("service: context probe %d", probes.Add(1))
+	ctx := context.WithValue(context.Background(), probeKey{}, want)
+	res, err := server.Serve(ctx, Request{})
+	if err != nil {
+		tb.Errorf("AssertContextPropagated: Serve() got err %v, wanted %v", err, nil)
+		return
+	}
+	if res.Data != want {
+		tb.Errorf("AssertContextPropagated: the work got context value %q, wanted %q", res.Data, want)
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeTB is a testing.TB recording the failures instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	failures int
+}
+
+// Helper does nothing.
+func (f *fakeTB) Helper() {}
+
+// Errorf records the failure.
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.failures++
+}
+
+// Test case for the assertions of a call exceeding the deadline of the context
+func TestTestService_Assert_DeadlineExceeded(t *testing.T) {
+	srv := &TestService{DelayReponse: time.Second}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	srv.Serve(ctx, Request{Data: "request"})
+
+	srv.AssertDeadlineExceeded(t)
+	srv.AssertServed(t, Request{Data: "request"})
+	tb := &fakeTB{}
+	srv.AssertCancelled(tb)
+	srv.AssertServed(tb, Request{Data: "other"})
+	if tb.failures != 2 {
+		t.Errorf("got %d failures, wanted %d", tb.failures, 2)
+	}
+}
+
+// Test case for the assertions of a call with a cancelled context
+func TestTestService_Assert_Cancelled(t *testing.T) {
+	srv := &TestService{DelayReponse: time.Second}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	srv.Serve(ctx, Request{})
+
+	srv.AssertCancelled(t)
+	tb := &fakeTB{}
+	srv.AssertDeadlineExceeded(tb)
+	if tb.failures != 1 {
+		t.Errorf("got %d failures, wanted %d", tb.failures, 1)
+	}
+}
+
+// Test case for the assertion of a request that was never served
+func TestTestService_Assert_NotServed(t *testing.T) {
+	srv := &TestService{}
+
+	tb := &fakeTB{}
+	srv.AssertServed(tb, Request{})
+
+	if tb.failures != 1 {
+		t.Errorf("got %d failures, wanted %d", tb.failures, 1)
+	}
+}
+
+// Benchmark using the assertions with a testing.B
+func BenchmarkTestService_Assert(b *testing.B) {
+	srv := &TestService{}
+	for i := 0; i < b.N; i++ {
+		srv.Serve(context.Background(), Request{Data: "request"})
+		srv.AssertServed(b, Request{Data: "request"})
+	}
+}
+
+// Test case for AssertContextPropagated. It passes for the Servers that derive the context of the work from the
+// context of the caller, and fails for a middleware replacing it.
+func TestAssertContextPropagated(t *testing.T) {
+	dropContext := func(next Server) Server {
+		return ServerFunc(func(ctx context.Context, req Request) (Response, error) {
+			return next.Serve(context.Background(), req)
+		})
+	}
+
+	tests := []struct {
+		name         string
+		server       Server
+		wantFailures int
+	}{
+		{name: "service", server: NewServiceWithOptions(ContextProbe, WithTimeout(time.Second), WithSingleFlight(nil)),
+			wantFailures: 0},
+		{name: "middleware", server: Chain(ServerFunc(ContextProbe), TimeoutMiddleware(time.Second)), wantFailures: 0},
+		{name: "context replaced", server: Chain(ServerFunc(ContextProbe), dropContext), wantFailures: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fakeTB{}
+			AssertContextPropagated(tb, tt.server)
+			if tb.failures != tt.wantFailures {
+				t.Errorf("got %d failures, wanted %d", tb.failures, tt.wantFailures)
+			}
+		})
+	}
+}
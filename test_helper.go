@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 )
 
@@ -12,51 +13,468 @@ type Server interface {
 	Serve(ctx context.Context, req Request) (Response, error)
 }
 
-// TestService is an implementation of the Server interface for testing purposes
-type TestService struct {
+// BatchServer is an interface to use in your code in order to be able to switch batch service implementations
+// between application and testing code
+type BatchServer interface {
+	ServeBatch(ctx context.Context, reqs []Request) ([]Response, []error)
+}
+
+// StreamServer is an interface to use in your code in order to be able to switch stream service implementations
+// between application and testing code
+type StreamServer interface {
+	ServeStream(ctx context.Context, req Request) (<-chan Response, <-chan error)
+}
+
+// TestService is an implementation of the Server interface for testing purposes. It is the GenericTestService of
+// the Request and Response types
+type TestService = GenericTestService[Request, Response]
+
+// GenericTestService is the generic variant of TestService, implementing the GenericServer interface for testing
+// services with typed requests and responses
+type GenericTestService[Req, Res any] struct {
 	// The response that should be returned
-	Res Response
+	Res Res
 	// DelayReponse is the time to delay the response of the test service.
 	// Should be used when testing with cancellable context
 	DelayReponse time.Duration
 	// Err is the error that should be returned
 	Err error
-	// Recorder stores informations about the Serve execution
+	// Responses are the responses that should be returned by call: the nth call returns Responses[n-1].
+	// Calls beyond the length of Responses return Res
+	Responses []Res
+	// Delays are the delays of the response by call: the nth call is delayed by Delays[n-1].
+	// Calls beyond the length of Delays are delayed by DelayReponse
+	Delays []time.Duration
+	// Errs are the errors that should be returned by call: the nth call returns Errs[n-1].
+	// Calls beyond the length of Errs return Err
+	Errs []error
+	// Synchronous makes the calls without delay return immediately, without waiting for a timer, so that the
+	// outcome is fully deterministic. An already cancelled context is still honored. Calls with a delay are not
+	// affected
+	Synchronous bool
+	// CompleteLate makes the calls whose context is done before their delay return the context error right away,
+	// but keep a goroutine running until the delay, like work ignoring its context, which records
+	// Recorder.LateCompletion and Recorder.LateBy when it completes. Should be used when testing the handling of late
+	// results. Use WaitLate for waiting for the late goroutines, so that none is left behind at the end of the test
+	CompleteLate bool
+	// ServerName is the name returned by Name, so that the logs of composite Servers tell which TestService served a
+	// request (see Named)
+	ServerName string
+	// RecordKeys are the keys of the context values that should be recorded in Recorder.CtxValues.
+	// Should be used when testing middleware that injects values (trace id, auth principal etc) in the context
+	RecordKeys []any
+	// Recorder stores informations about the Serve execution. Use Snapshot for reading it while Serve may still
+	// be running
+	Recorder GenericTestRecorder[Req, Res]
+
+	// mu guards the Recorder, since Serve may be called in parallel
+	mu sync.Mutex
+	// late tracks the goroutines of the calls completing late (see CompleteLate)
+	late sync.WaitGroup
+}
+
+// TestRecorder stores informations about the Serve execution of a TestService
+type TestRecorder = GenericTestRecorder[Request, Response]
+
+// GenericTestRecorder stores informations about the Serve execution of a GenericTestService
+type GenericTestRecorder[Req, Res any] struct {
+	// Request is the actual request that was served
+	Request Req
+	// CtxCancelled is a flag showing if the context was cancelled or not
+	CtxCancelled bool
+	// CtxCancelled is a flag showing if the context exceeded a deadline
+	CtxDeadlineExceeded bool
+	// CtxErr is the error returned in case of context cancellation.
+	CtxErr error
+	// CtxCause is the cause of the context cancellation (see context.Cause), which is the same as CtxErr unless
+	// the context was cancelled with a cause.
+	CtxCause error
+	// CtxValues are the values found in the context for every key of RecordKeys.
+	// It is nil when RecordKeys is empty.
+	CtxValues map[any]any
+	// Calls is the number of times Serve was called
+	Calls int
+	// Delay is the delay used by the last call
+	Delay time.Duration
+	// ReturnedResponse is the response returned by the last call, either the predefined response or the zero
+	// response in case of context cancellation
+	ReturnedResponse Res
+	// ReturnedErr is the error returned by the last call, either the predefined error or the context error in
+	// case of context cancellation
+	ReturnedErr error
+	// LateCompletion is a flag showing if the last call completed after its context was done (see CompleteLate)
+	LateCompletion bool
+	// LateBy is the time from the context of the last call being done until the call completed (see CompleteLate)
+	LateBy time.Duration
+}
+
+// Name returns the ServerName of the TestService
+func (t *GenericTestService[Req, Res]) Name() string {
+	return t.ServerName
+}
+
+// Snapshot returns a copy of the Recorder, that can be read safely even while Serve is being called in parallel
+func (t *GenericTestService[Req, Res]) Snapshot() GenericTestRecorder[Req, Res] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r := t.Recorder
+	if t.Recorder.CtxValues != nil {
+		r.CtxValues = make(map[any]any, len(t.Recorder.CtxValues))
+		for k, v := range t.Recorder.CtxValues {
+			r.CtxValues[k] = v
+		}
+	}
+
+	return r
+}
+
+// Serve serves and records the request and context cancellation and error, and replys back with
+// a predefined response or error
+func (t *GenericTestService[Req, Res]) Serve(ctx context.Context, req Req) (Res, error) {
+	// record the request param and the context values, count the call and pick the predefined response and error
+	// of the call
+	res, delay, err := t.record(ctx, req)
+	// the zero response is returned along with the context error
+	var zero Res
+
+	// return the predefined response right away in synchronous mode, unless the context is already done
+	if t.Synchronous && delay == 0 {
+		if ctx.Err() != nil {
+			return zero, t.recordCtxErr(ctx)
+		}
+		t.recordReturned(res, err)
+		return res, err
+	}
+
+	// create a timer to signal that the actual work was finished. Unlike a sleeping goroutine, the timer is
+	// stopped and released as soon as the context gets cancelled
+	timer := time.NewTimer(delay)
+
+	select {
+	case <-ctx.Done():
+		if !t.CompleteLate {
+			timer.Stop()
+			return zero, t.recordCtxErr(ctx)
+		}
+		// keep running until the delay on a goroutine, like work ignoring its context, while the caller gets the
+		// context error right away
+		ctxErr := t.recordCtxErr(ctx)
+		done := time.Now()
+		t.late.Add(1)
+		go func() {
+			defer t.late.Done()
+			<-timer.C
+			t.recordLate(time.Since(done))
+		}()
+		return zero, ctxErr
+	case <-timer.C:
+		t.recordReturned(res, err)
+		return res, err
+	}
+}
+
+// recordCtxErr records the error of the cancelled context as the returned error, and returns it.
+func (t *GenericTestService[Req, Res]) recordCtxErr(ctx context.Context) error {
+	err := ctx.Err()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Recorder.CtxErr = err
+	t.Recorder.CtxCause = context.Cause(ctx)
+	if errors.Is(err, context.Canceled) {
+		t.Recorder.CtxCancelled = true
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		t.Recorder.CtxDeadlineExceeded = true
+	}
+	var zero Res
+	t.Recorder.ReturnedResponse = zero
+	t.Recorder.ReturnedErr = err
+
+	return err
+}
+
+// recordReturned records the response and the error returned by Serve.
+func (t *GenericTestService[Req, Res]) recordReturned(res Res, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Recorder.ReturnedResponse = res
+	t.Recorder.ReturnedErr = err
+}
+
+// WaitLate blocks until the goroutines of the calls completing late have completed (see CompleteLate).
+func (t *GenericTestService[Req, Res]) WaitLate() {
+	t.late.Wait()
+}
+
+// recordLate records the completion of a call after its context was done, and how late.
+func (t *GenericTestService[Req, Res]) recordLate(lateBy time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Recorder.LateCompletion = true
+	t.Recorder.LateBy = lateBy
+}
+
+// record records the request and the context values, counts the call and returns the predefined response, delay
+// and error of the call.
+func (t *GenericTestService[Req, Res]) record(ctx context.Context, req Req) (Res, time.Duration, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Recorder.Request = req
+	if len(t.RecordKeys) > 0 {
+		t.Recorder.CtxValues = make(map[any]any, len(t.RecordKeys))
+		for _, key := range t.RecordKeys {
+			t.Recorder.CtxValues[key] = ctx.Value(key)
+		}
+	}
+
+	i := t.Recorder.Calls
+	t.Recorder.Calls++
+	t.Recorder.LateCompletion = false
+	t.Recorder.LateBy = 0
+
+	res, err := t.Res, t.Err
+	if i < len(t.Responses) {
+		res = t.Responses[i]
+	}
+	if i < len(t.Errs) {
+		err = t.Errs[i]
+	}
+	delay := t.DelayReponse
+	if i < len(t.Delays) {
+		delay = t.Delays[i]
+	}
+	t.Recorder.Delay = delay
+
+	return res, delay, err
+}
+
+// FuncService is an implementation of the Server interface for testing purposes, whose behavior is programmed with
+// a function instead of predefined responses. It is useful when the test needs arbitrary logic per call, e.g. a
+// stateful mock failing the first time and succeeding after.
+type FuncService struct {
+	// Fn is called by Serve and its outcome is returned. A nil Fn returns a zero Response and a nil error
+	Fn func(ctx context.Context, req Request) (Response, error)
+	// Recorder stores informations about the Serve executions
+	Recorder struct {
+		// Calls are the calls of Serve, in the order they returned
+		Calls []FuncCall
+	}
+
+	// mu guards the recording of the calls, since Serve may be called in parallel
+	mu sync.Mutex
+}
+
+// FuncCall is a call of FuncService.Serve
+type FuncCall struct {
+	// Request is the request that was served
+	Request Request
+	// Response is the response returned by Fn
+	Response Response
+	// Err is the error returned by Fn
+	Err error
+}
+
+// Serve calls Fn, records the request along with the returned response and error, and returns them
+func (f *FuncService) Serve(ctx context.Context, req Request) (Response, error) {
+	var res Response
+	var err error
+	if f.Fn != nil {
+		res, err = f.Fn(ctx, req)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Recorder.Calls = append(f.Recorder.Calls, FuncCall{Request: req, Response: res, Err: err})
+
+	return res, err
+}
+
+// TestBatchService is an implementation of the BatchServer interface for testing purposes. The requests of a batch
+// are served concurrently, and every one of them replys back with the predefined response and error of its index
+// in the batch, after the delay of its index, unless the context of the batch gets cancelled first
+type TestBatchService struct {
+	// The response that should be returned for the indexes beyond the length of Responses
+	Res Response
+	// Err is the error that should be returned for the indexes beyond the length of Errs
+	Err error
+	// DelayReponse is the time to delay the response for the indexes beyond the length of Delays
+	DelayReponse time.Duration
+	// Responses are the responses that should be returned by index: reqs[i] gets Responses[i]
+	Responses []Response
+	// Errs are the errors that should be returned by index: reqs[i] gets Errs[i]
+	Errs []error
+	// Delays are the delays of the responses by index: reqs[i] is delayed by Delays[i]
+	Delays []time.Duration
+	// Recorder stores informations about the ServeBatch executions. Use Snapshot for reading it while ServeBatch
+	// may still be running
+	Recorder TestBatchRecorder
+
+	// mu guards the Recorder, since the requests are served in parallel
+	mu sync.Mutex
+}
+
+// TestBatchRecorder stores informations about the ServeBatch executions of a TestBatchService
+type TestBatchRecorder struct {
+	// Requests are all the requests received, in the order of the batches and of the requests in every batch
+	Requests []Request
+	// Batches is the number of times ServeBatch was called
+	Batches int
+	// Served is the number of requests that replied back with the predefined response or error
+	Served int
+	// Cancelled is the number of requests that returned the context error instead
+	Cancelled int
+}
+
+// Snapshot returns a copy of the Recorder, that can be read safely even while ServeBatch is being called in parallel
+func (t *TestBatchService) Snapshot() TestBatchRecorder {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r := t.Recorder
+	r.Requests = append([]Request(nil), t.Recorder.Requests...)
+
+	return r
+}
+
+// ServeBatch serves and records the requests concurrently, and returns their predefined responses and errors aligned
+// by index with the requests. The requests still being served when the context gets cancelled return the context
+// error
+func (t *TestBatchService) ServeBatch(ctx context.Context, reqs []Request) ([]Response, []error) {
+	t.mu.Lock()
+	t.Recorder.Batches++
+	t.Recorder.Requests = append(t.Recorder.Requests, reqs...)
+	t.mu.Unlock()
+
+	responses := make([]Response, len(reqs))
+	errs := make([]error, len(reqs))
+
+	var wg sync.WaitGroup
+	for i := range reqs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = t.serve(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+
+	return responses, errs
+}
+
+// serve replys back with the predefined response and error of the ith request of a batch.
+func (t *TestBatchService) serve(ctx context.Context, i int) (Response, error) {
+	res, err, delay := t.Res, t.Err, t.DelayReponse
+	if i < len(t.Responses) {
+		res = t.Responses[i]
+	}
+	if i < len(t.Errs) {
+		err = t.Errs[i]
+	}
+	if i < len(t.Delays) {
+		delay = t.Delays[i]
+	}
+
+	// use a timer instead of time.Sleep, so that no goroutine lingers after the cancellation
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	// an already cancelled context wins over a zero delay
+	if ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+			t.mu.Lock()
+			t.Recorder.Served++
+			t.mu.Unlock()
+			return res, err
+		}
+	}
+
+	t.mu.Lock()
+	t.Recorder.Cancelled++
+	t.mu.Unlock()
+
+	return Response{}, ctx.Err()
+}
+
+// TestStreamService is an implementation of the StreamServer interface for testing purposes.
+// It replays a predefined stream of responses, optionally delaying every response.
+type TestStreamService struct {
+	// Responses is the stream of responses that should be replayed, in order
+	Responses []Response
+	// Delay is the time to wait before sending every response.
+	// Should be used when testing with cancellable context
+	Delay time.Duration
+	// Err is the error that should be returned after the responses
+	Err error
+	// Recorder stores informations about the ServeStream execution
 	Recorder struct {
 		// Request is the actual request that was served
 		Request Request
+		// Sent is the number of responses received by the caller
+		Sent int
 		// CtxCancelled is a flag showing if the context was cancelled or not
 		CtxCancelled bool
-		// CtxCancelled is a flag showing if the context exceeded a deadline
+		// CtxDeadlineExceeded is a flag showing if the context exceeded a deadline
 		CtxDeadlineExceeded bool
 		// CtxErr is the error returned in case of context cancellation.
 		CtxErr error
 	}
 }
 
-// Serve serves and records the request and context cancellation and error, and replys back with
-// a predefined response or error
-func (t *TestService) Serve(ctx context.Context, req Request) (Response, error) {
+// ServeStream serves and records the request and context cancellation and error, and replays the predefined
+// responses followed by the predefined error. The Recorder can be read after the returned channels are closed.
+func (t *TestStreamService) ServeStream(ctx context.Context, req Request) (<-chan Response, <-chan error) {
 	// record the request param
 	t.Recorder.Request = req
 
-	// create a channel to signal that the actual work was finished
-	done := make(chan bool, 1)
+	resCh := make(chan Response)
+	errCh := make(chan error, 1)
 	go func() {
-		time.Sleep(t.DelayReponse)
-		done <- true
-	}()
+		defer close(errCh)
+		defer close(resCh)
 
-	select {
-	case <-ctx.Done():
-		t.Recorder.CtxErr = ctx.Err()
-		if errors.Is(ctx.Err(), context.Canceled) {
-			t.Recorder.CtxCancelled = true
-		} else if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-			t.Recorder.CtxDeadlineExceeded = true
+		for _, res := range t.Responses {
+			// use a timer instead of time.Sleep, so that no goroutine lingers after the cancellation
+			timer := time.NewTimer(t.Delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				errCh <- t.recordCtxErr(ctx)
+				return
+			}
+
+			select {
+			case resCh <- res:
+				t.Recorder.Sent++
+			case <-ctx.Done():
+				errCh <- t.recordCtxErr(ctx)
+				return
+			}
 		}
-		return Response{}, ctx.Err()
-	case <-done:
-		return t.Res, t.Err
+
+		if t.Err != nil {
+			errCh <- t.Err
+		}
+	}()
+
+	return resCh, errCh
+}
+
+// recordCtxErr records the error of the cancelled context and returns it.
+func (t *TestStreamService) recordCtxErr(ctx context.Context) error {
+	t.Recorder.CtxErr = ctx.Err()
+	if errors.Is(ctx.Err(), context.Canceled) {
+		t.Recorder.CtxCancelled = true
+	} else if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Recorder.CtxDeadlineExceeded = true
 	}
+
+	return ctx.Err()
 }
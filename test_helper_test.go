@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test case for recording context values. The values injected by the caller are recorded, and missing ones are nil.
+func TestTestService_Serve_RecordKeys(t *testing.T) {
+	type traceIDKey struct{}
+	type principalKey struct{}
+	srv := &TestService{
+		RecordKeys: []any{traceIDKey{}, principalKey{}},
+	}
+
+	ctx := context.WithValue(context.Background(), traceIDKey{}, "trace-1")
+	srv.Serve(ctx, Request{})
+
+	want := map[any]any{traceIDKey{}: "trace-1", principalKey{}: nil}
+	if !reflect.DeepEqual(srv.Recorder.CtxValues, want) {
+		t.Errorf("got context values %v, wanted %v", srv.Recorder.CtxValues, want)
+	}
+}
+
+// Test case for no keys to record. No context values are recorded.
+func TestTestService_Serve_NoRecordKeys(t *testing.T) {
+	srv := &TestService{}
+
+	srv.Serve(context.Background(), Request{})
+
+	if srv.Recorder.CtxValues != nil {
+		t.Errorf("got context values %v, wanted nil", srv.Recorder.CtxValues)
+	}
+}
+
+// Test case for sequential responses. The first call fails and the retry succeeds.
+func TestTestService_Serve_Sequential(t *testing.T) {
+	firstErr := errors.New("error")
+	srv := &TestService{
+		Res:       Response{Data: "default"},
+		Responses: []Response{{}, {Data: "second"}},
+		Errs:      []error{firstErr},
+	}
+
+	tests := []struct {
+		wantRes Response
+		wantErr error
+	}{
+		{wantRes: Response{}, wantErr: firstErr},
+		{wantRes: Response{Data: "second"}, wantErr: nil},
+		{wantRes: Response{Data: "default"}, wantErr: nil},
+	}
+	for i, tt := range tests {
+		res, err := srv.Serve(context.Background(), Request{})
+		if !reflect.DeepEqual(res, tt.wantRes) || err != tt.wantErr {
+			t.Errorf("call %d: Serve() got %v, %v, wanted %v, %v", i+1, res, err, tt.wantRes, tt.wantErr)
+		}
+	}
+	if srv.Recorder.Calls != 3 {
+		t.Errorf("got %d calls, wanted %d", srv.Recorder.Calls, 3)
+	}
+}
+
+// Test case for a TestService used by a retrying Service. The retry gets the response of the second call.
+func TestTestService_Serve_WithRetry(t *testing.T) {
+	th := &TestService{
+		Responses: []Response{{}, {Data: "success"}},
+		Errs:      []error{errors.New("error")},
+	}
+	srv := NewServiceWithOptions(th.Serve, WithRetry(3, 0))
+
+	res, err := srv.Serve(context.Background(), Request{})
+
+	if err != nil || res.Data != "success" {
+		t.Errorf("Serve() got %v, %v, wanted %v, %v", res, err, Response{Data: "success"}, nil)
+	}
+	if th.Recorder.Calls != 2 {
+		t.Errorf("got %d calls, wanted %d", th.Recorder.Calls, 2)
+	}
+}
+
+// Test case for parallel calls. Every call is counted.
+func TestTestService_Serve_ParallelCalls(t *testing.T) {
+	srv := &TestService{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			srv.Serve(context.Background(), Request{})
+		}()
+	}
+	wg.Wait()
+
+	if srv.Recorder.Calls != 50 {
+		t.Errorf("got %d calls, wanted %d", srv.Recorder.Calls, 50)
+	}
+}
+
+// Test case for per-call delays. The first call is slow and times out, the second is fast.
+func TestTestService_Serve_Delays(t *testing.T) {
+	srv := &TestService{
+		Res:          Response{Data: "success"},
+		Delays:       []time.Duration{time.Second, 0},
+		DelayReponse: 2 * time.Second,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := srv.Serve(ctx, Request{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if srv.Recorder.Delay != time.Second {
+		t.Errorf("got delay %v, wanted %v", srv.Recorder.Delay, time.Second)
+	}
+
+	if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+	if srv.Recorder.Delay != 0 {
+		t.Errorf("got delay %v, wanted %v", srv.Recorder.Delay, 0)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	srv.Serve(ctx, Request{})
+	if srv.Recorder.Delay != 2*time.Second {
+		t.Errorf("got delay %v, wanted %v", srv.Recorder.Delay, 2*time.Second)
+	}
+}
+
+// Test case for hedging against a TestService. The slow first call triggers a hedge that wins with the fast second.
+func TestTestService_Serve_DelaysWithHedging(t *testing.T) {
+	th := &TestService{
+		Responses: []Response{{Data: "slow"}, {Data: "fast"}},
+		Delays:    []time.Duration{time.Second, 0},
+	}
+	srv := NewServiceWithOptions(th.Serve, WithHedging(20*time.Millisecond, 1))
+
+	res, err := srv.Serve(context.Background(), Request{})
+
+	if err != nil || res.Data != "fast" {
+		t.Errorf("Serve() got %v, %v, wanted %v, %v", res, err, Response{Data: "fast"}, nil)
+	}
+	if srv.Hedges() != 1 {
+		t.Errorf("Hedges() got %d, wanted %d", srv.Hedges(), 1)
+	}
+}
+
+// Test case for many timed out calls. No goroutine lingers after the cancellation.
+func TestTestService_Serve_NoGoroutineLeak(t *testing.T) {
+	srv := &TestService{
+		DelayReponse: time.Minute,
+	}
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		srv.Serve(ctx, Request{})
+		cancel()
+	}
+
+	if !srv.Recorder.CtxDeadlineExceeded {
+		t.Errorf("CtxDeadlineExceeded should be true")
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("got %d goroutines after the calls, wanted at most %d", after, before)
+	}
+}
+
+// Test case for recording the returned values, on both the work and the context paths
+func TestTestService_Serve_Returned(t *testing.T) {
+	workErr := errors.New("error")
+	srv := &TestService{
+		Responses: []Response{{Data: "first"}},
+		Errs:      []error{nil, workErr},
+		Delays:    []time.Duration{0, 0, time.Second},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	tests := []struct {
+		ctx     context.Context
+		wantRes Response
+		wantErr error
+	}{
+		{ctx: context.Background(), wantRes: Response{Data: "first"}, wantErr: nil},
+		{ctx: context.Background(), wantRes: Response{}, wantErr: workErr},
+		{ctx: ctx, wantRes: Response{}, wantErr: context.DeadlineExceeded},
+	}
+	for i, tt := range tests {
+		srv.Serve(tt.ctx, Request{})
+		if !reflect.DeepEqual(srv.Recorder.ReturnedResponse, tt.wantRes) || srv.Recorder.ReturnedErr != tt.wantErr {
+			t.Errorf("call %d: got returned %v, %v, wanted %v, %v",
+				i+1, srv.Recorder.ReturnedResponse, srv.Recorder.ReturnedErr, tt.wantRes, tt.wantErr)
+		}
+	}
+}
+
+// Test case for a call completing after its context is done. Serve returns the context error right away, and the
+// late completion is recorded once the delay is over, while without CompleteLate nothing completes late.
+func TestTestService_Serve_CompleteLate(t *testing.T) {
+	for _, completeLate := range []bool{false, true} {
+		before := runtime.NumGoroutine()
+		ts := &TestService{Res: Response{Data: "late"}, DelayReponse: 100 * time.Millisecond, CompleteLate: completeLate}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+
+		start := time.Now()
+		_, err := ts.Serve(ctx, Request{})
+		elapsed := time.Since(start)
+		cancel()
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+		}
+		if elapsed >= 50*time.Millisecond {
+			t.Errorf("CompleteLate %v: Serve() returned after %v, wanted before the delay", completeLate, elapsed)
+		}
+		ts.WaitLate()
+		r := ts.Snapshot()
+		if !r.CtxDeadlineExceeded || r.ReturnedErr != context.DeadlineExceeded {
+			t.Errorf("CompleteLate %v: got returned err %v, wanted %v", completeLate, r.ReturnedErr,
+				context.DeadlineExceeded)
+		}
+		if r.LateCompletion != completeLate || (r.LateBy >= 50*time.Millisecond) != completeLate {
+			t.Errorf("CompleteLate %v: got late completion %v by %v", completeLate, r.LateCompletion, r.LateBy)
+		}
+		// WaitLate waited for the late goroutine, so no goroutine is left behind.
+		if after := runtime.NumGoroutine(); after > before {
+			t.Errorf("got %d goroutines after WaitLate, wanted at most %d", after, before)
+		}
+	}
+}
+
+// Test case for a call whose context is cancelled with a cause, in every mode. The recorded error is the one
+// returned to the caller.
+func TestTestService_Serve_ReturnedCtxErr(t *testing.T) {
+	modes := []*TestService{
+		{Synchronous: true},
+		{DelayReponse: 100 * time.Millisecond},
+		{DelayReponse: 100 * time.Millisecond, CompleteLate: true},
+	}
+	for i, ts := range modes {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(errors.New("cause"))
+
+		_, err := ts.Serve(ctx, Request{})
+		ts.WaitLate()
+
+		if r := ts.Snapshot(); err != context.Canceled || r.ReturnedErr != err {
+			t.Errorf("mode %d: Serve() got err %v and recorded %v, wanted %v for both", i+1, err, r.ReturnedErr,
+				context.Canceled)
+		}
+	}
+}
+
+// Test case for a stateful FuncService failing the first time and succeeding after
+func TestFuncService_Serve(t *testing.T) {
+	workErr := errors.New("error")
+	calls := 0
+	srv := &FuncService{
+		Fn: func(ctx context.Context, req Request) (Response, error) {
+			calls++
+			if calls == 1 {
+				return Response{}, workErr
+			}
+			return Response{Data: req.Data}, nil
+		},
+	}
+
+	if _, err := srv.Serve(context.Background(), Request{Data: "first"}); err != workErr {
+		t.Errorf("Serve() got err %v, wanted %v", err, workErr)
+	}
+	if res, err := srv.Serve(context.Background(), Request{Data: "second"}); err != nil || res.Data != "second" {
+		t.Errorf("Serve() got %v, %v, wanted %v, %v", res, err, Response{Data: "second"}, nil)
+	}
+
+	want := []FuncCall{
+		{Request: Request{Data: "first"}, Err: workErr},
+		{Request: Request{Data: "second"}, Response: Response{Data: "second"}},
+	}
+	if !reflect.DeepEqual(srv.Recorder.Calls, want) {
+		t.Errorf("got calls %+v, wanted %+v", srv.Recorder.Calls, want)
+	}
+}
+
+// Test case for a FuncService without function. It returns a zero Response and a nil error.
+func TestFuncService_Serve_NilFn(t *testing.T) {
+	srv := &FuncService{}
+
+	res, err := srv.Serve(context.Background(), Request{})
+
+	if err != nil || !reflect.DeepEqual(res, Response{}) {
+		t.Errorf("Serve() got %v, %v, wanted %v, %v", res, err, Response{}, nil)
+	}
+	if len(srv.Recorder.Calls) != 1 {
+		t.Errorf("got %d calls, wanted %d", len(srv.Recorder.Calls), 1)
+	}
+}
+
+// Test case for the synchronous mode. Calls without delay return the predefined values, unless the context is done.
+func TestTestService_Serve_Synchronous(t *testing.T) {
+	workErr := errors.New("error")
+	srv := &TestService{
+		Synchronous: true,
+		Res:         Response{Data: "success"},
+		Errs:        []error{nil, workErr},
+	}
+
+	for i := 0; i < 100; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := srv.Serve(ctx, Request{}); err != context.Canceled {
+			t.Fatalf("Serve() got err %v, wanted %v", err, context.Canceled)
+		}
+	}
+	srv.AssertCancelled(t)
+
+	srv = &TestService{Synchronous: true, Res: Response{Data: "success"}, Errs: []error{nil, workErr}}
+	if res, err := srv.Serve(context.Background(), Request{}); err != nil || res.Data != "success" {
+		t.Errorf("Serve() got %v, %v, wanted %v, %v", res, err, Response{Data: "success"}, nil)
+	}
+	if _, err := srv.Serve(context.Background(), Request{}); err != workErr {
+		t.Errorf("Serve() got err %v, wanted %v", err, workErr)
+	}
+	if srv.Recorder.ReturnedErr != workErr || srv.Recorder.Calls != 2 {
+		t.Errorf("got returned err %v and %d calls, wanted %v and %d", srv.Recorder.ReturnedErr, srv.Recorder.Calls, workErr, 2)
+	}
+}
+
+// Test case for parallel calls sharing a TestService, some of them cancelled, while the Recorder is read with
+// Snapshot. Run with -race to detect unsynchronized access.
+func TestTestService_Snapshot_ParallelCalls(t *testing.T) {
+	type key struct{}
+	srv := &TestService{DelayReponse: 5 * time.Millisecond, RecordKeys: []any{key{}}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := context.WithValue(context.Background(), key{}, i)
+			if i%2 == 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				cancel()
+			}
+			srv.Serve(ctx, Request{})
+		}(i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if snapshot := srv.Snapshot(); snapshot.CtxValues != nil {
+				snapshot.CtxValues[key{}] = "changed"
+			}
+		}()
+	}
+	wg.Wait()
+
+	snapshot := srv.Snapshot()
+	if snapshot.Calls != 50 {
+		t.Errorf("got %d calls, wanted %d", snapshot.Calls, 50)
+	}
+	if !snapshot.CtxCancelled {
+		t.Errorf("CtxCancelled should be true")
+	}
+	if snapshot.CtxValues[key{}] == "changed" {
+		t.Errorf("changing a snapshot should not change the Recorder")
+	}
+}
+
+// Test case for a batch with per index responses, errors and delays, cancelled before the slowest request returns
+func TestTestBatchService_ServeBatch(t *testing.T) {
+	wantErr := errors.New("error")
+	ts := &TestBatchService{
+		Responses: []Response{{Data: "first"}, {}, {Data: "third"}},
+		Errs:      []error{nil, wantErr},
+		Delays:    []time.Duration{0, 0, time.Minute},
+	}
+	reqs := []Request{{Data: "a"}, {Data: "b"}, {Data: "c"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	responses, errs := ts.ServeBatch(ctx, reqs)
+
+	wantResponses := []Response{{Data: "first"}, {}, {}}
+	if !reflect.DeepEqual(responses, wantResponses) {
+		t.Errorf("ServeBatch() got responses %v, wanted %v", responses, wantResponses)
+	}
+	wantErrs := []error{nil, wantErr, context.DeadlineExceeded}
+	if !reflect.DeepEqual(errs, wantErrs) {
+		t.Errorf("ServeBatch() got errs %v, wanted %v", errs, wantErrs)
+	}
+	wantRecorder := TestBatchRecorder{Requests: reqs, Batches: 1, Served: 2, Cancelled: 1}
+	if got := ts.Snapshot(); !reflect.DeepEqual(got, wantRecorder) {
+		t.Errorf("got Recorder %+v, wanted %+v", got, wantRecorder)
+	}
+}
+
+// Test case for a batch with an already cancelled context. No request is served, even without delay.
+func TestTestBatchService_ServeBatch_Cancelled(t *testing.T) {
+	ts := &TestBatchService{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, errs := ts.ServeBatch(ctx, []Request{{}, {}})
+
+	for i, err := range errs {
+		if err != context.Canceled {
+			t.Errorf("ServeBatch() got errs[%d] %v, wanted %v", i, err, context.Canceled)
+		}
+	}
+	if got := ts.Snapshot(); got.Served != 0 || got.Cancelled != 2 {
+		t.Errorf("got %d served and %d cancelled requests, wanted %d and %d", got.Served, got.Cancelled, 0, 2)
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// WithTimeout is an option that bounds every call of Serve to d, even if the caller context has a later deadline or
+// no deadline at all. The time spent waiting for the rate limiter and for a concurrency slot counts towards d.
+// When d is reached the work context is cancelled and Serve returns context.DeadlineExceeded (possibly wrapped, see
+// Serve), exactly like when the deadline of the caller is exceeded. A cancellation by the caller is still returned
+// as context.Canceled. Values lower or equal to zero disable the timeout, and negative values are rejected by New.
+func WithTimeout(d time.Duration) Option {
+	return func(s *Service) {
+		if d < 0 {
+			s.invalidOption("WithTimeout(%v): negative timeout", d)
+		}
+		s.timeout = d
+	}
+}
+
+// WithMaxDeadline is an option that caps the deadline of every call of Serve to d from now, so that a caller passing
+// a very long deadline, or none at all, can't tie up the downstream indefinitely: the context of the work expires at
+// the earliest of the deadline of the caller, the timeout of WithTimeout and d from the call, whatever the order of
+// the options. Values lower or equal to zero disable the cap, and negative values are rejected by New.
+func WithMaxDeadline(d time.Duration) Option {
+	return func(s *Service) {
+		if d < 0 {
+			s.invalidOption("WithMaxDeadline(%v): negative deadline", d)
+		}
+		s.maxDeadline = d
+	}
+}
+
+// WithTimeoutFromRequest is an option that bounds every call of Serve to the timeout returned by fn for the request,
+// e.g. a longer timeout for the requests of premium users, so that requests with different SLAs can be served by the
+// same Service. It works like WithTimeout, and the earliest of the deadlines of the caller, of WithTimeout and of
+// the request applies. A return value lower or equal to zero means that the request has no timeout of its own.
+func WithTimeoutFromRequest(fn func(Request) time.Duration) Option {
+	return func(s *Service) {
+		s.requestTimeout = fn
+	}
+}
+
+// WithPerAttemptTimeout is an option that bounds every attempt of the work to d (see WithRetry), so that a single
+// slow attempt doesn't consume the whole budget of the call and leave no time for the retries. The context of each
+// attempt expires after d or at the deadline of the call, whatever comes first, so the deadline of the call still
+// bounds the total time spent on all the attempts. An attempt that times out returns context.DeadlineExceeded, which
+// is retried like any other error, unless the predicate of WithRetryIf rejects it.
+// Values lower or equal to zero disable the timeout, and negative values are rejected by New.
+func WithPerAttemptTimeout(d time.Duration) Option {
+	return func(s *Service) {
+		if d < 0 {
+			s.invalidOption("WithPerAttemptTimeout(%v): negative timeout", d)
+		}
+		s.attemptTimeout = d
+	}
+}
+
+// WithBudgetDecay is an option that splits the remaining time until the deadline of the call evenly among the
+// attempts left (see WithRetry), so that attempt k of n gets remaining/(n-k+1) and the last attempt is not left
+// without time by the earlier ones. The time of each attempt is capped by WithPerAttemptTimeout, if it is used.
+// The waits between the attempts are not accounted for, so they are taken from the share of the next attempts.
+// It has no effect on the calls without deadline.
+func WithBudgetDecay(enabled bool) Option {
+	return func(s *Service) {
+		s.budgetDecay = enabled
+	}
+}
+
+// attemptTimeoutOf returns the timeout of the next attempt, given the attempts left including it, or 0 if the
+// attempt is bounded only by the deadline of the call.
+func (s *Service) attemptTimeoutOf(ctx context.Context, attemptsLeft int) time.Duration {
+	timeout := s.attemptTimeout
+	if !s.budgetDecay || attemptsLeft < 1 {
+		return timeout
+	}
+	remaining, ok := RemainingBudget(ctx)
+	if !ok || remaining <= 0 {
+		return timeout
+	}
+	if share := remaining / time.Duration(attemptsLeft); timeout <= 0 || share < timeout {
+		timeout = share
+	}
+
+	return timeout
+}
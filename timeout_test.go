@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test case for the timeout of the Service being shorter than the deadline of the caller
+func TestService_Serve_TimeoutShorter(t *testing.T) {
+	ts := &TestService{DelayReponse: time.Second}
+	srv := NewServiceWithOptions(ts.Serve, WithTimeout(20*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Serve() returned after %v, wanted about %v", elapsed, 20*time.Millisecond)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("caller context got err %v, wanted %v", ctx.Err(), nil)
+	}
+}
+
+// Test case for the deadline of the caller being shorter than the timeout of the Service
+func TestService_Serve_TimeoutCallerShorter(t *testing.T) {
+	ts := &TestService{DelayReponse: time.Second}
+	srv := NewServiceWithOptions(ts.Serve, WithTimeout(time.Minute))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Serve() returned after %v, wanted about %v", elapsed, 20*time.Millisecond)
+	}
+}
+
+// Test case for the caller cancelling before the timeout of the Service
+func TestService_Serve_TimeoutCallerCancelled(t *testing.T) {
+	ts := &TestService{DelayReponse: time.Second}
+	srv := NewServiceWithOptions(ts.Serve, WithTimeout(time.Minute))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.Canceled)
+	}
+}
+
+// Test case for attempts timing out at the per attempt timeout. Every attempt gets its own timeout, and all of them
+// run before the deadline of the caller.
+func TestService_Serve_PerAttemptTimeout(t *testing.T) {
+	ts := &TestService{DelayReponse: time.Second}
+	srv := NewServiceWithOptions(ts.Serve, WithRetry(3, 0), WithPerAttemptTimeout(20*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if srv.Attempts() != 3 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 3)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Serve() returned after %v, wanted about %v", elapsed, 60*time.Millisecond)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("caller context got err %v, wanted %v", ctx.Err(), nil)
+	}
+}
+
+// Test case for the deadline of the caller firing before the attempts are exhausted. The deadline of the caller
+// bounds the total time of the attempts.
+func TestService_Serve_PerAttemptTimeoutCallerShorter(t *testing.T) {
+	ts := &TestService{DelayReponse: time.Second}
+	srv := NewServiceWithOptions(ts.Serve, WithRetry(10, 0), WithPerAttemptTimeout(30*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := srv.Serve(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if attempts := srv.Attempts(); attempts > 3 {
+		t.Errorf("Attempts() got %d, wanted at most %d", attempts, 3)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Serve() returned after %v, wanted about %v", elapsed, 50*time.Millisecond)
+	}
+}
+
+// Test case for the timeout derived from the request. Requests with different timeouts are served by the same
+// Service, and a zero timeout means no timeout.
+func TestService_Serve_TimeoutFromRequest(t *testing.T) {
+	ts := &TestService{DelayReponse: 100 * time.Millisecond}
+	srv := NewServiceWithOptions(ts.Serve, WithTimeoutFromRequest(func(req Request) time.Duration {
+		switch req.Data {
+		case "premium":
+			return time.Minute
+		case "free":
+			return 10 * time.Millisecond
+		}
+		return 0
+	}))
+
+	tests := []struct {
+		tier string
+		err  error
+	}{
+		{tier: "premium", err: nil},
+		{tier: "free", err: context.DeadlineExceeded},
+		{tier: "unknown", err: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tier, func(t *testing.T) {
+			_, err := srv.Serve(context.Background(), Request{Data: tt.tier})
+			if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+				t.Errorf("Serve() got err %v, wanted %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// Test case for the budget decay. Every attempt gets a share of the remaining time, so the remaining time shrinks
+// with every attempt, the last attempt still gets its share and the deadline of the call is never exceeded.
+func TestService_Serve_BudgetDecay(t *testing.T) {
+	tests := []struct {
+		name           string
+		attemptTimeout time.Duration
+		maxFirst       time.Duration
+	}{
+		{name: "decay", maxFirst: 100 * time.Millisecond},
+		{name: "capped by per attempt timeout", attemptTimeout: 40 * time.Millisecond, maxFirst: 40 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var deadlines []time.Time
+			srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+				deadline, _ := ctx.Deadline()
+				mu.Lock()
+				deadlines = append(deadlines, deadline)
+				mu.Unlock()
+				<-ctx.Done()
+				return Response{}, ctx.Err()
+			}, WithRetry(3, 0), WithBudgetDecay(true), WithPerAttemptTimeout(tt.attemptTimeout))
+			start := time.Now()
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+			callDeadline, _ := ctx.Deadline()
+
+			srv.Serve(ctx, Request{})
+			srv.Close(context.Background())
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(deadlines) != 3 {
+				t.Fatalf("got %d attempts, wanted %d", len(deadlines), 3)
+			}
+			if first := deadlines[0].Sub(start); first > tt.maxFirst+10*time.Millisecond {
+				t.Errorf("first attempt got %v, wanted at most %v", first, tt.maxFirst)
+			}
+			for i, deadline := range deadlines {
+				if deadline.After(callDeadline) {
+					t.Errorf("attempt %d got deadline %v after the deadline of the call %v", i, deadline, callDeadline)
+				}
+				if i > 0 && !deadline.After(deadlines[i-1]) {
+					t.Errorf("attempt %d got deadline %v, wanted after the one of the previous attempt %v", i, deadline,
+						deadlines[i-1])
+				}
+			}
+			// The remaining time shrinks with every attempt.
+			for i := 1; i < len(deadlines); i++ {
+				if callDeadline.Sub(deadlines[i]) >= callDeadline.Sub(deadlines[i-1]) {
+					t.Errorf("attempt %d left %v of the call, wanted less than %v", i, callDeadline.Sub(deadlines[i]),
+						callDeadline.Sub(deadlines[i-1]))
+				}
+			}
+		})
+	}
+}
+
+// Test case for the maximum deadline. The deadline of the work is the earliest of the deadline of the caller and the
+// maximum deadline, which applies to the callers without deadline too.
+func TestService_Serve_MaxDeadline(t *testing.T) {
+	tests := []struct {
+		name          string
+		callerTimeout time.Duration
+		minRemaining  time.Duration
+		maxRemaining  time.Duration
+	}{
+		{name: "caller longer", callerTimeout: time.Hour, minRemaining: 50 * time.Second, maxRemaining: time.Minute},
+		{name: "caller shorter", callerTimeout: time.Second, minRemaining: 0, maxRemaining: time.Second},
+		{name: "no caller deadline", minRemaining: 50 * time.Second, maxRemaining: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var remaining time.Duration
+			var hasDeadline bool
+			srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+				var deadline time.Time
+				deadline, hasDeadline = ctx.Deadline()
+				remaining = time.Until(deadline)
+				return Response{}, nil
+			}, WithMaxDeadline(time.Minute))
+			ctx := context.Background()
+			if tt.callerTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.callerTimeout)
+				defer cancel()
+			}
+
+			if _, err := srv.Serve(ctx, Request{}); err != nil {
+				t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+			}
+
+			if !hasDeadline || remaining <= tt.minRemaining || remaining > tt.maxRemaining {
+				t.Errorf("work got %v until the deadline (deadline %v), wanted within (%v, %v]", remaining,
+					hasDeadline, tt.minRemaining, tt.maxRemaining)
+			}
+		})
+	}
+}
+
+// Test case for the maximum deadline along with the timeout of the Service. The earliest of the two applies, whatever
+// the order of the options.
+func TestService_Serve_MaxDeadlineWithTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "timeout first", opts: []Option{WithTimeout(time.Second), WithMaxDeadline(time.Minute)}},
+		{name: "max deadline first", opts: []Option{WithMaxDeadline(time.Minute), WithTimeout(time.Second)}},
+		{name: "shorter max deadline first", opts: []Option{WithMaxDeadline(time.Second), WithTimeout(time.Minute)}},
+		{name: "shorter max deadline last", opts: []Option{WithTimeout(time.Minute), WithMaxDeadline(time.Second)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var remaining time.Duration
+			srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+				remaining, _ = RemainingBudget(ctx)
+				return Response{}, nil
+			}, tt.opts...)
+
+			if _, err := srv.Serve(context.Background(), Request{}); err != nil {
+				t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+			}
+
+			if remaining <= 0 || remaining > time.Second {
+				t.Errorf("work got %v until the deadline, wanted at most %v", remaining, time.Second)
+			}
+		})
+	}
+}
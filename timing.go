@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// ServeTimed serves the request like Serve, and also returns the duration of the work, separate from the overhead
+// of Serve (e.g. waiting for the rate limiter), in order to tell apart slow work from aggressive deadlines.
+// The duration is measured from just before launching the work until its outcome is received. When the context
+// gets cancelled first, the duration is the time elapsed until the cancellation, so it is a lower bound of the
+// actual duration of the work. The duration is 0 when the work is not launched at all, e.g. on a cache hit or when
+// the context gets cancelled while waiting for the rate limiter.
+func (s *Service) ServeTimed(ctx context.Context, req Request) (Response, time.Duration, error) {
+	var d details
+	res, err := s.run(ctx, req, &d)
+
+	return res, d.workDuration, err
+}
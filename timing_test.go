@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test case for the happy path. The duration covers the work.
+func TestService_ServeTimed_Success(t *testing.T) {
+	srv := NewService(func() (Response, error) {
+		time.Sleep(50 * time.Millisecond)
+		return Response{Data: "success"}, nil
+	})
+
+	_, duration, err := srv.ServeTimed(context.Background(), Request{})
+
+	if err != nil {
+		t.Errorf("ServeTimed() should not return an error, got %v", err)
+	}
+	if duration < 50*time.Millisecond || duration > 1000*time.Millisecond {
+		t.Errorf("ServeTimed() got duration %v, wanted about %v", duration, 50*time.Millisecond)
+	}
+}
+
+// Test case for a timeout. The duration is the time elapsed until the timeout.
+func TestService_ServeTimed_Timeout(t *testing.T) {
+	srv := NewService(func() (Response, error) {
+		time.Sleep(2000 * time.Millisecond)
+		return Response{Data: "success"}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, duration, err := srv.ServeTimed(ctx, Request{})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ServeTimed() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if duration < 50*time.Millisecond || duration > 1000*time.Millisecond {
+		t.Errorf("ServeTimed() got duration %v, wanted about %v", duration, 50*time.Millisecond)
+	}
+}
+
+// Test case for a wait for the rate limiter. The wait is not part of the duration.
+func TestService_ServeTimed_ExcludesOverhead(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: "success"}, nil
+	}, WithRateLimit(10, 1))
+
+	srv.Serve(context.Background(), Request{})
+	start := time.Now()
+	_, duration, err := srv.ServeTimed(context.Background(), Request{})
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Errorf("ServeTimed() should not return an error, got %v", err)
+	}
+	if elapsed < 50*time.Millisecond || duration > elapsed/2 {
+		t.Errorf("ServeTimed() got duration %v after %v, wanted the rate limiter wait to be excluded", duration, elapsed)
+	}
+}
+
+// Test case for a cache hit. The work is not launched, so the duration is 0.
+func TestService_ServeTimed_CacheHit(t *testing.T) {
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: "success"}, nil
+	}, WithCache(time.Minute, nil))
+
+	srv.Serve(context.Background(), Request{})
+	_, duration, _ := srv.ServeTimed(context.Background(), Request{})
+
+	if duration != 0 {
+		t.Errorf("ServeTimed() got duration %v, wanted %v", duration, 0)
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Names used by the OpenTelemetry instrumentation of WithTracer.
+const (
+	// SpanName is the name of the span created for every call of Serve.
+	SpanName = "service.Serve"
+	// EventWorkStart is the name of the span event recorded when the work is launched.
+	EventWorkStart = "work.start"
+	// EventWorkFinish is the name of the span event recorded when the work returns.
+	EventWorkFinish = "work.finish"
+	// AttributeRequestDataLength is the name of the span attribute holding the length of Request.Data.
+	AttributeRequestDataLength = "service.request.data_length"
+	// AttributeOutcome is the name of the span attribute holding the outcome of Serve (see the Outcome constants).
+	AttributeOutcome = "service.outcome"
+)
+
+// WithTracer is an option that creates an OpenTelemetry span named SpanName for every call of Serve, as a child of
+// the span of the incoming context, if there is one. The span records an event when the work is launched and when
+// it returns, and its status is set to Error on failure, with a description telling apart the timeout and the
+// cancellation of the context from any other error. The context passed to the work carries the span, so that the
+// spans of any downstream call made by the work nest correctly.
+func WithTracer(tracer trace.Tracer) Option {
+	return func(s *Service) {
+		s.tracer = tracer
+	}
+}
+
+// startSpan starts the span of Serve, returning a context that carries it.
+func (s *Service) startSpan(ctx context.Context, req Request) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, SpanName, trace.WithAttributes(
+		attribute.Int(AttributeRequestDataLength, len(req.Data)),
+	))
+}
+
+// endSpan sets the status of the span of Serve according to the returned error, and ends it.
+func endSpan(span trace.Span, err error) {
+	o := outcome(err)
+	span.SetAttributes(attribute.String(AttributeOutcome, o))
+	switch o {
+	case OutcomeSuccess:
+		span.SetStatus(codes.Ok, "")
+	case OutcomeError:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	default:
+		// The request was not served because of the context, so use the outcome as description.
+		span.SetStatus(codes.Error, o)
+	}
+	span.End()
+}
+
+// traceEvent adds an event to the span of Serve, if tracing is enabled.
+func (s *Service) traceEvent(ctx context.Context, name string) {
+	if s.tracer == nil {
+		return
+	}
+
+	trace.SpanFromContext(ctx).AddEvent(name)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestTracer returns a tracer that records the ended spans in the returned recorder.
+func newTestTracer() (trace.Tracer, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	return provider.Tracer("test"), recorder
+}
+
+// Test case for the happy path. The span is a child of the incoming span, and the work sees the span of Serve.
+func TestService_Serve_TracingSuccess(t *testing.T) {
+	tracer, recorder := newTestTracer()
+	var workSpan trace.SpanContext
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		workSpan = trace.SpanFromContext(ctx).SpanContext()
+		return Response{Data: "success"}, nil
+	}, WithTracer(tracer))
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	_, err := srv.Serve(ctx, Request{Data: "request"})
+	parent.End()
+
+	if err != nil {
+		t.Errorf("Serve() should not return an error, got %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, wanted %d", len(spans), 2)
+	}
+	span := spans[0]
+	if span.Name() != SpanName {
+		t.Errorf("got span %q, wanted %q", span.Name(), SpanName)
+	}
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("got parent span %v, wanted %v", span.Parent().SpanID(), parent.SpanContext().SpanID())
+	}
+	if workSpan.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("work got span %v, wanted %v", workSpan.SpanID(), span.SpanContext().SpanID())
+	}
+	if span.Status().Code != codes.Ok {
+		t.Errorf("got status %v, wanted %v", span.Status().Code, codes.Ok)
+	}
+
+	var events []string
+	for _, e := range span.Events() {
+		events = append(events, e.Name)
+	}
+	if len(events) != 2 || events[0] != EventWorkStart || events[1] != EventWorkFinish {
+		t.Errorf("got events %v, wanted %v", events, []string{EventWorkStart, EventWorkFinish})
+	}
+
+	found := false
+	for _, a := range span.Attributes() {
+		if string(a.Key) == AttributeRequestDataLength {
+			found = true
+			if a.Value.AsInt64() != int64(len("request")) {
+				t.Errorf("got %s %v, wanted %v", AttributeRequestDataLength, a.Value.AsInt64(), len("request"))
+			}
+		}
+	}
+	if !found {
+		t.Errorf("attribute %s not found", AttributeRequestDataLength)
+	}
+}
+
+// Test case for a failing work. The span status is Error with the error message.
+func TestService_Serve_TracingError(t *testing.T) {
+	tracer, recorder := newTestTracer()
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, errors.New("work error")
+	}, WithTracer(tracer))
+
+	srv.Serve(context.Background(), Request{})
+
+	span := recorder.Ended()[0]
+	if span.Status().Code != codes.Error || span.Status().Description != "work error" {
+		t.Errorf("got status %+v, wanted %v with description %q", span.Status(), codes.Error, "work error")
+	}
+}
+
+// Test case for a timeout. The span status is Error with the timeout outcome as description.
+func TestService_Serve_TracingTimeout(t *testing.T) {
+	tracer, recorder := newTestTracer()
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		<-ctx.Done()
+		return Response{}, ctx.Err()
+	}, WithTracer(tracer))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	srv.Serve(ctx, Request{})
+
+	span := recorder.Ended()[0]
+	if span.Status().Code != codes.Error || span.Status().Description != OutcomeTimeout {
+		t.Errorf("got status %+v, wanted %v with description %q", span.Status(), codes.Error, OutcomeTimeout)
+	}
+}
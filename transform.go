@@ -0,0 +1,14 @@
+package service
+
+import "context"
+
+// WithResponseTransform is an option that post-processes every response of the work before Serve returns it, e.g.
+// for redacting or normalizing fields, without modifying the work itself.
+// The transform runs only when the work succeeds, never on the error or the timeout paths. The transformed response
+// is the one cached (see WithCache), so cache hits are not transformed again. An error of the transform becomes the
+// error of Serve and, like any error, it is replaced by the response of the fallback if there is one.
+func WithResponseTransform(transform func(ctx context.Context, req Request, res Response) (Response, error)) Option {
+	return func(s *Service) {
+		s.transform = transform
+	}
+}
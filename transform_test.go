@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test case for the transform of a successful response
+func TestService_Serve_ResponseTransform(t *testing.T) {
+	ts := &TestService{Res: Response{Data: "secret data"}}
+	srv := NewServiceWithOptions(ts.Serve, WithResponseTransform(func(ctx context.Context, req Request, res Response) (Response, error) {
+		res.Data = strings.Replace(res.Data, "secret", "[redacted]", 1)
+		return res, nil
+	}))
+
+	response, err := srv.Serve(context.Background(), Request{})
+
+	if err != nil || !reflect.DeepEqual(response, Response{Data: "[redacted] data"}) {
+		t.Errorf("Serve() got %v, %v, wanted %v, %v", response, err, Response{Data: "[redacted] data"}, nil)
+	}
+}
+
+// Test case for the error of the transform becoming the error of Serve
+func TestService_Serve_ResponseTransformError(t *testing.T) {
+	transformErr := errors.New("transform error")
+	ts := &TestService{Res: Response{Data: "success"}}
+	srv := NewServiceWithOptions(ts.Serve, WithResponseTransform(func(ctx context.Context, req Request, res Response) (Response, error) {
+		return Response{}, transformErr
+	}))
+
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if !errors.Is(err, transformErr) {
+		t.Errorf("Serve() got err %v, wanted %v", err, transformErr)
+	}
+}
+
+// Test case for the transform not running on the error and the timeout paths
+func TestService_Serve_ResponseTransformSkipped(t *testing.T) {
+	workErr := errors.New("error")
+	ts := &TestService{Errs: []error{workErr}, Delays: []time.Duration{0, time.Second}}
+	transformed := 0
+	srv := NewServiceWithOptions(ts.Serve, WithResponseTransform(func(ctx context.Context, req Request, res Response) (Response, error) {
+		transformed++
+		return res, nil
+	}))
+
+	if _, err := srv.Serve(context.Background(), Request{}); !errors.Is(err, workErr) {
+		t.Errorf("Serve() got err %v, wanted %v", err, workErr)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := srv.Serve(ctx, Request{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Serve() got err %v, wanted %v", err, context.DeadlineExceeded)
+	}
+	if transformed != 0 {
+		t.Errorf("the transform was called %d times, wanted %d", transformed, 0)
+	}
+}
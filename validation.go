@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrValidation is the error wrapped by the errors of Serve caused by a request rejected by the validator
+// (see WithValidator).
+var ErrValidation = errors.New("service: invalid request")
+
+// WithValidator is an option that validates every request before serving it. A request rejected by the validator
+// is not served at all: Serve returns immediately, before the cache, the rate limiter, the retries and the fallback,
+// and the work is never called.
+// The returned error wraps both ErrValidation and the error of the validator, so errors.Is(err, ErrValidation)
+// classifies the validation failures while the error of the validator is still accessible.
+func WithValidator(validate func(req Request) error) Option {
+	return func(s *Service) {
+		s.validate = validate
+	}
+}
+
+// validateRequest validates the request, if there is a validator.
+func (s *Service) validateRequest(req Request) error {
+	if s.validate == nil {
+		return nil
+	}
+	if err := s.validate(req); err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	return nil
+}
+
+// ErrInvalidResult is the error wrapped by the errors of Serve caused by a response of the work rejected by the
+// result validator (see WithResultValidator).
+var ErrInvalidResult = errors.New("service: invalid result")
+
+// WithResultValidator is an option that checks every successful response of the work, e.g. for empty data or a
+// stale timestamp, so that the soft failures that don't surface as errors are caught. A response rejected by the
+// validator is treated as a failed attempt: it is retried like any other error (see WithRetry and WithRetryIf),
+// counts as a failure of the circuit breaker and doesn't win a hedged race. Its body is released, since nobody is
+// going to read it. The returned error wraps both ErrInvalidResult and the error of the validator.
+func WithResultValidator(validate func(res Response) error) Option {
+	return func(s *Service) {
+		s.validateResult = validate
+	}
+}
+
+// checkResult validates the response of the work, if there is a result validator.
+func (s *Service) checkResult(res Response) error {
+	if s.validateResult == nil {
+		return nil
+	}
+	if err := s.validateResult(res); err != nil {
+		DrainAndClose(res.Body)
+		return fmt.Errorf("%w: %w", ErrInvalidResult, err)
+	}
+
+	return nil
+}
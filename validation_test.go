@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test case for a request rejected by the validator. The work, the rate limiter and the fallback are never called.
+func TestService_Serve_ValidatorRejected(t *testing.T) {
+	invalid := errors.New("empty data")
+	ts := &TestService{Res: Response{Data: "success"}}
+	fallbackCalled := false
+	srv := NewServiceWithOptions(ts.Serve,
+		WithValidator(func(req Request) error {
+			if req.Data == "" {
+				return invalid
+			}
+			return nil
+		}),
+		WithRetry(3, 0),
+		WithRateLimit(1, 1),
+		WithRateLimitMode(RateLimitReject),
+		WithCache(time.Minute, nil),
+		WithFallback(func(ctx context.Context, req Request, cause error) (Response, error) {
+			fallbackCalled = true
+			return Response{}, nil
+		}),
+	)
+
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if !errors.Is(err, ErrValidation) || !errors.Is(err, invalid) {
+		t.Errorf("Serve() got err %v, wanted %v and %v", err, ErrValidation, invalid)
+	}
+	if ts.Recorder.Calls != 0 {
+		t.Errorf("got %d calls, wanted %d", ts.Recorder.Calls, 0)
+	}
+	if fallbackCalled {
+		t.Errorf("the fallback should not be called")
+	}
+
+	// The rejected request didn't consume the single token of the rate limiter.
+	response, err := srv.Serve(context.Background(), Request{Data: "valid"})
+	if err != nil || !reflect.DeepEqual(response, Response{Data: "success"}) {
+		t.Errorf("Serve() got %v, %v, wanted %v, %v", response, err, Response{Data: "success"}, nil)
+	}
+}
+
+// Test case for the Meta of the request. A value derived by the validator is read later by the logger of the same
+// request.
+func TestService_Serve_RequestMeta(t *testing.T) {
+	var logged any
+	srv := NewServiceWithOptions(noopWork,
+		WithValidator(func(req Request) error {
+			req.Meta["tier"] = strings.ToUpper(req.Data)
+			return nil
+		}),
+		WithLogger(func(ctx context.Context, event LogEvent) {
+			logged = event.Request.Meta["tier"]
+		}))
+
+	if _, err := srv.Serve(context.Background(), Request{Data: "premium"}); err != nil {
+		t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+	}
+	if logged != "PREMIUM" {
+		t.Errorf("logger got meta %v, wanted %v", logged, "PREMIUM")
+	}
+}
+
+// Test case for SetMeta, creating the Meta of the request on the first write.
+func TestRequest_SetMeta(t *testing.T) {
+	var req Request
+
+	req.SetMeta("tier", "premium")
+	req.SetMeta("region", "eu")
+
+	want := map[string]any{"tier": "premium", "region": "eu"}
+	if !reflect.DeepEqual(req.Meta, want) {
+		t.Errorf("SetMeta() got meta %v, wanted %v", req.Meta, want)
+	}
+}
+
+// Test case for the goroutines serving a request concurrently: the work, the hedged copies and the shadow. Each one
+// writes its own copy of the Meta, leaving the Meta of the caller untouched. Run it with the race detector.
+func TestService_Serve_RequestMetaConcurrent(t *testing.T) {
+	work := func(ctx context.Context, req Request) (Response, error) {
+		req.Meta["written"] = true
+		time.Sleep(20 * time.Millisecond)
+		return Response{}, nil
+	}
+	shadow := ServerFunc(work)
+	srv := NewServiceWithOptions(work, WithHedging(time.Millisecond, 3), WithShadow(shadow, 1))
+	meta := map[string]any{"tier": "premium"}
+
+	if _, err := srv.Serve(context.Background(), Request{Meta: meta}); err != nil {
+		t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+	}
+	if err := srv.Close(context.Background()); err != nil {
+		t.Fatalf("Close() got err %v, wanted %v", err, nil)
+	}
+
+	if want := map[string]any{"tier": "premium"}; !reflect.DeepEqual(meta, want) {
+		t.Errorf("the caller got meta %v, wanted %v", meta, want)
+	}
+}
+
+// Test case for the result validator. The first response is invalid and it is retried, and the second one is valid.
+func TestService_Serve_ResultValidatorRetry(t *testing.T) {
+	ts := &TestService{Responses: []Response{{Data: ""}, {Data: "valid"}}, Synchronous: true}
+	errEmpty := errors.New("empty data")
+	srv := NewServiceWithOptions(ts.Serve, WithRetry(3, 0), WithResultValidator(func(res Response) error {
+		if res.Data == "" {
+			return errEmpty
+		}
+		return nil
+	}))
+
+	res, err := srv.Serve(context.Background(), Request{})
+
+	if err != nil || res.Data != "valid" {
+		t.Errorf("Serve() got %v and err %v, wanted %v", res, err, Response{Data: "valid"})
+	}
+	if srv.Attempts() != 2 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+	}
+}
+
+// Test case for the result validator rejecting every response. The retries are exhausted and the error wraps both
+// ErrInvalidResult and the error of the validator.
+func TestService_Serve_ResultValidatorExhausted(t *testing.T) {
+	errStale := errors.New("stale")
+	srv := NewServiceWithOptions(noopWork, WithRetry(2, 0), WithResultValidator(func(res Response) error {
+		return errStale
+	}))
+
+	_, err := srv.Serve(context.Background(), Request{})
+
+	if !errors.Is(err, ErrInvalidResult) || !errors.Is(err, errStale) {
+		t.Errorf("Serve() got err %v, wanted %v and %v", err, ErrInvalidResult, errStale)
+	}
+	if srv.Attempts() != 2 {
+		t.Errorf("Attempts() got %d, wanted %d", srv.Attempts(), 2)
+	}
+}
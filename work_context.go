@@ -0,0 +1,16 @@
+package service
+
+// WithWorkContext is an option that cancels the context passed to the work as soon as Serve returns, even if the
+// context of the caller is still alive. This way any goroutine or downstream call started by the work with this
+// context stops when the request is over, instead of running orphaned until the caller cancels its context.
+// Only work receiving the context (see NewServiceCtx) is affected. Work that ignores the context, like the work of
+// NewService, keeps running until it returns.
+// It suits work making gRPC calls with the context it receives: gRPC sends the deadline of the context of a call to
+// the server, and the work context already carries the earliest of the deadline of the caller and the timeouts of the
+// Service (see WithTimeout and WithDefaultTimeout), so with this option the gRPC calls of abandoned work are also
+// cancelled on the server instead of running until their deadline.
+func WithWorkContext() Option {
+	return func(s *Service) {
+		s.workContext = true
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test case for the work context being cancelled as soon as Serve returns, while the caller context is still alive
+func TestService_Serve_WorkContext(t *testing.T) {
+	var workCtx context.Context
+	srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+		workCtx = ctx
+		return Response{Data: "success"}, nil
+	}, WithWorkContext())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := srv.Serve(ctx, Request{}); err != nil {
+		t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+	}
+
+	if !errors.Is(workCtx.Err(), context.Canceled) {
+		t.Errorf("work context got err %v, wanted %v", workCtx.Err(), context.Canceled)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("caller context got err %v, wanted %v", ctx.Err(), nil)
+	}
+}
+
+// Test case for the work context outliving Serve without WithWorkContext
+func TestService_Serve_WorkContextDisabled(t *testing.T) {
+	var workCtx context.Context
+	srv := NewServiceCtx(func(ctx context.Context, req Request) (Response, error) {
+		workCtx = ctx
+		return Response{Data: "success"}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := srv.Serve(ctx, Request{}); err != nil {
+		t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+	}
+
+	if workCtx.Err() != nil {
+		t.Errorf("work context got err %v, wanted %v", workCtx.Err(), nil)
+	}
+}
+
+// Test case for the deadline seen by the work, e.g. sent to the server by a gRPC call, which is the earliest of the
+// deadline of the caller and the timeout of the Service, and for the work context being cancelled when Serve returns
+func TestService_Serve_WorkContextDeadline(t *testing.T) {
+	tests := []struct {
+		name     string
+		deadline time.Duration
+		timeout  time.Duration
+		want     time.Duration
+	}{
+		{name: "caller deadline", deadline: 50 * time.Millisecond, timeout: time.Minute, want: 50 * time.Millisecond},
+		{name: "service timeout", deadline: time.Minute, timeout: 50 * time.Millisecond, want: 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var workCtx context.Context
+			var remaining time.Duration
+			srv := NewServiceWithOptions(func(ctx context.Context, req Request) (Response, error) {
+				workCtx = ctx
+				remaining, _ = RemainingBudget(ctx)
+				return Response{}, nil
+			}, WithWorkContext(), WithTimeout(tt.timeout))
+			ctx, cancel := context.WithTimeout(context.Background(), tt.deadline)
+			defer cancel()
+
+			if _, err := srv.Serve(ctx, Request{}); err != nil {
+				t.Fatalf("Serve() got err %v, wanted %v", err, nil)
+			}
+
+			if remaining > tt.want || remaining < tt.want-20*time.Millisecond {
+				t.Errorf("work got deadline in %v, wanted about %v", remaining, tt.want)
+			}
+			if workCtx.Err() != context.Canceled {
+				t.Errorf("work context got err %v after Serve returned, wanted %v", workCtx.Err(), context.Canceled)
+			}
+		})
+	}
+}